@@ -0,0 +1,246 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/SCU-SJL/easymock/easymock"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type Client struct {
+	baseURL      string
+	httpClient   *http.Client
+	mu           sync.Mutex
+	expectations []expectation
+}
+
+type expectation struct {
+	id          string
+	expectation easymock.CallExpectation
+}
+
+type APIError struct {
+	StatusCode int
+	Messages   []string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("easymock admin api returned %d: %s", e.StatusCode, strings.Join(e.Messages, "; "))
+}
+
+// New returns a client for the admin api of the easymock server listening
+// on baseURL, such as http://localhost:8080. The server has to enable the
+// admin api, see EasyMocker.EnableAdmin and the -admin flag of the command.
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/") + easymock.AdminPrefix,
+		httpClient: &http.Client{Transport: easymock.OriginTransport},
+	}
+}
+
+func (c *Client) WithHTTPClient(httpClient *http.Client) *Client {
+	c.httpClient = httpClient
+	return c
+}
+
+func (c *Client) Register(def easymock.RouteDefinition) (easymock.RouteInfo, error) {
+	var info easymock.RouteInfo
+	inlined, err := def.Inline()
+	if err != nil {
+		return info, err
+	}
+	err = c.do(http.MethodPost, "routes", inlined, &info)
+	return info, err
+}
+
+func (c *Client) RegisterAll(defs []easymock.RouteDefinition) ([]easymock.RouteInfo, error) {
+	inlined := make([]easymock.RouteDefinition, 0, len(defs))
+	for i := range defs {
+		def, err := defs[i].Inline()
+		if err != nil {
+			return nil, err
+		}
+		inlined = append(inlined, def)
+	}
+	infos := make([]easymock.RouteInfo, 0, len(defs))
+	err := c.do(http.MethodPost, "routes", inlined, &infos)
+	return infos, err
+}
+
+// LoadDefinitions registers the definition files found at path, the same
+// files easymock.LoadDefinitions reads for an in-process mocker.
+func (c *Client) LoadDefinitions(path string) ([]easymock.RouteInfo, error) {
+	defs, err := easymock.LoadDefinitions(path)
+	if err != nil {
+		return nil, err
+	}
+	return c.RegisterAll(defs)
+}
+
+func (c *Client) Routes() ([]easymock.RouteInfo, error) {
+	infos := make([]easymock.RouteInfo, 0)
+	err := c.do(http.MethodGet, "routes", nil, &infos)
+	return infos, err
+}
+
+func (c *Client) Route(id string) (easymock.RouteInfo, error) {
+	var info easymock.RouteInfo
+	err := c.do(http.MethodGet, "routes/"+url.PathEscape(id), nil, &info)
+	return info, err
+}
+
+func (c *Client) Remove(id string) error {
+	return c.do(http.MethodDelete, "routes/"+url.PathEscape(id), nil, nil)
+}
+
+func (c *Client) Enable(id string) error {
+	return c.do(http.MethodPost, "routes/"+url.PathEscape(id)+"/enable", nil, nil)
+}
+
+func (c *Client) Disable(id string) error {
+	return c.do(http.MethodPost, "routes/"+url.PathEscape(id)+"/disable", nil, nil)
+}
+
+func (c *Client) CallCounts() (easymock.CallCounts, error) {
+	var counts easymock.CallCounts
+	err := c.do(http.MethodGet, "counts", nil, &counts)
+	return counts, err
+}
+
+func (c *Client) CallCount(id string) (int, error) {
+	info, err := c.Route(id)
+	return info.Calls, err
+}
+
+func (c *Client) Journal() ([]easymock.JournalRecord, error) {
+	records := make([]easymock.JournalRecord, 0)
+	err := c.do(http.MethodGet, "journal", nil, &records)
+	return records, err
+}
+
+func (c *Client) UnmatchedRequests() ([]easymock.JournalRecord, error) {
+	records := make([]easymock.JournalRecord, 0)
+	err := c.do(http.MethodGet, "journal?unmatched=true", nil, &records)
+	return records, err
+}
+
+func (c *Client) ResetJournal() error {
+	return c.do(http.MethodDelete, "journal", nil, nil)
+}
+
+// Reset clears the server and the expectations kept by this client.
+func (c *Client) Reset() error {
+	c.mu.Lock()
+	c.expectations = nil
+	c.mu.Unlock()
+	return c.do(http.MethodPost, "reset", nil, nil)
+}
+
+func (c *Client) Scenarios() (map[string]string, error) {
+	scenarios := make(map[string]string)
+	err := c.do(http.MethodGet, "scenarios", nil, &scenarios)
+	return scenarios, err
+}
+
+func (c *Client) SetScenarioState(name, state string) error {
+	return c.do(http.MethodPut, "scenarios/"+url.PathEscape(name), map[string]string{"state": state}, nil)
+}
+
+func (c *Client) ResetScenarios() error {
+	return c.do(http.MethodDelete, "scenarios", nil, nil)
+}
+
+// ExpectCalls records an expectation on the route with the given id. It is
+// kept by the client and checked against the server by AssertExpectations.
+func (c *Client) ExpectCalls(id string, e easymock.CallExpectation) {
+	c.mu.Lock()
+	c.expectations = append(c.expectations, expectation{id: id, expectation: e})
+	c.mu.Unlock()
+}
+
+func (c *Client) UnmetExpectations() ([]string, error) {
+	c.mu.Lock()
+	expectations := make([]expectation, len(c.expectations))
+	copy(expectations, c.expectations)
+	c.mu.Unlock()
+
+	unmet := make([]string, 0)
+	for _, e := range expectations {
+		info, err := c.Route(e.id)
+		if err != nil {
+			return nil, err
+		}
+		if !e.expectation.IsMet(info.Calls) {
+			unmet = append(unmet, fmt.Sprintf("%s %s (%s): expected %s, got %d",
+				info.Method, info.URL, info.ID, e.expectation, info.Calls))
+		}
+	}
+	return unmet, nil
+}
+
+func (c *Client) AssertExpectations(t testing.TB) bool {
+	t.Helper()
+	unmet, err := c.UnmetExpectations()
+	if err != nil {
+		t.Errorf("easymock: cannot check expectations: %v", err)
+		return false
+	}
+	if len(unmet) == 0 {
+		return true
+	}
+	t.Errorf("easymock: %d expectation(s) not met:\n  %s", len(unmet), strings.Join(unmet, "\n  "))
+	return false
+}
+
+func (c *Client) do(method, path string, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = b
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var apiErr struct {
+			Error  string   `json:"error"`
+			Errors []string `json:"errors"`
+		}
+		_ = json.Unmarshal(data, &apiErr)
+		messages := apiErr.Errors
+		if apiErr.Error != "" {
+			messages = append(messages, apiErr.Error)
+		}
+		if len(messages) == 0 {
+			messages = []string{strings.TrimSpace(string(data))}
+		}
+		return &APIError{StatusCode: resp.StatusCode, Messages: messages}
+	}
+	if out == nil || len(data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
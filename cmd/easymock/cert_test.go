@@ -0,0 +1,52 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+)
+
+func TestSelfSignedCert(t *testing.T) {
+	cert, err := selfSignedCert([]string{"localhost", " 127.0.0.1", "", "::1"})
+	assert.Nil(t, err)
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"localhost"}, leaf.DNSNames)
+	assert.Len(t, leaf.IPAddresses, 2)
+	assert.True(t, leaf.IPAddresses[0].Equal(net.ParseIP("127.0.0.1")))
+	assert.Nil(t, leaf.VerifyHostname("localhost"))
+	assert.NotNil(t, leaf.VerifyHostname("example.com"))
+}
+
+func TestServeHTTPSWithSelfSignedCert(t *testing.T) {
+	srv, dir := newTestServer(t, false)
+	defer os.RemoveAll(dir)
+
+	cert, err := selfSignedCert([]string{"127.0.0.1"})
+	assert.Nil(t, err)
+	server := httptest.NewUnstartedServer(srv)
+	server.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	server.StartTLS()
+	defer server.Close()
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	assert.Nil(t, err)
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+	cli := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots}}}
+
+	resp, err := cli.Get(server.URL + "/books")
+	if assert.Nil(t, err) {
+		defer resp.Body.Close()
+		body, err := ioutil.ReadAll(resp.Body)
+		assert.Nil(t, err)
+		assert.Equal(t, "books", string(body))
+		assert.Equal(t, []*x509.Certificate{leaf}, resp.TLS.PeerCertificates)
+	}
+}
@@ -0,0 +1,77 @@
+// Command easymock serves the json and yaml route definitions of a directory
+// over http and https, reloading them whenever the files change.
+//
+//	easymock -dir mocks -addr 127.0.0.1:8080 -tls-addr 127.0.0.1:8443 -base-url https://api.example.com
+//
+// The admin api under /__easymock/ is only served with -admin.
+package main
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	dir := flag.String("dir", "mocks", "directory holding the json and yaml route definitions")
+	addr := flag.String("addr", "127.0.0.1:8080", "address of the http listener, empty to disable")
+	tlsAddr := flag.String("tls-addr", "", "address of the https listener, empty to disable")
+	certFile := flag.String("cert", "", "certificate file of the https listener, a self-signed one is generated if empty")
+	keyFile := flag.String("key", "", "private key file of the https listener")
+	tlsHosts := flag.String("tls-hosts", "localhost,127.0.0.1,::1", "comma separated hosts of the self-signed certificate")
+	baseURL := flag.String("base-url", "", "serve every request as if it had been sent to this url")
+	admin := flag.Bool("admin", false, "serve the admin api, which lets any client change the routes")
+	poll := flag.Duration("poll", time.Second, "interval for checking definition changes, 0 to disable hot reload")
+	flag.Parse()
+
+	if *addr == "" && *tlsAddr == "" {
+		log.Fatal("easymock: at least one of -addr or -tls-addr is required")
+	}
+
+	srv, err := newMockServer(*dir, *baseURL, *admin)
+	if err != nil {
+		log.Fatalf("easymock: %v", err)
+	}
+	if *poll > 0 {
+		go srv.watch(*poll)
+	}
+	handler := logRequests(srv)
+
+	errCh := make(chan error, 2)
+	if *addr != "" {
+		log.Printf("easymock: serving %s on http://%s", *dir, *addr)
+		go func() {
+			errCh <- http.ListenAndServe(*addr, handler)
+		}()
+	}
+	if *tlsAddr != "" {
+		server := &http.Server{Addr: *tlsAddr, Handler: handler}
+		if *certFile == "" {
+			cert, err := selfSignedCert(strings.Split(*tlsHosts, ","))
+			if err != nil {
+				log.Fatalf("easymock: %v", err)
+			}
+			server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+		}
+		log.Printf("easymock: serving %s on https://%s", *dir, *tlsAddr)
+		go func() {
+			errCh <- server.ListenAndServeTLS(*certFile, *keyFile)
+		}()
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	select {
+	case err := <-errCh:
+		log.Fatalf("easymock: %v", err)
+	case sig := <-signals:
+		fmt.Fprintf(os.Stderr, "easymock: %v received, shutting down\n", sig)
+	}
+}
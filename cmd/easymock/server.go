@@ -0,0 +1,156 @@
+package main
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type mockServer struct {
+	dir      string
+	mocker   *easymock.EasyMocker
+	handler  http.Handler
+	snapshot map[string]time.Time
+}
+
+func newMockServer(dir, baseURL string, admin bool) (*mockServer, error) {
+	srv := &mockServer{dir: dir, mocker: easymock.NewEasyMockerTransport()}
+	srv.handler = srv.mocker
+	if baseURL != "" {
+		handler, err := srv.mocker.Handler(baseURL)
+		if err != nil {
+			return nil, err
+		}
+		srv.handler = handler
+	}
+	if admin {
+		srv.mocker.EnableAdmin()
+	}
+	snapshot, err := scanDefinitions(dir)
+	if err != nil {
+		return nil, err
+	}
+	if err := srv.reload(); err != nil {
+		return nil, err
+	}
+	srv.snapshot = snapshot
+	return srv, nil
+}
+
+func (srv *mockServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	srv.handler.ServeHTTP(w, r)
+}
+
+// reload replaces the routes loaded from the definition directory only if
+// every definition is valid, so a half edited file never takes down the
+// routes that are being served. Routes added through the admin api, counts,
+// the journal and scenario states survive a reload.
+func (srv *mockServer) reload() error {
+	defs, err := easymock.LoadDefinitions(srv.dir)
+	if err != nil {
+		return err
+	}
+	if err := srv.mocker.ReloadDefinitions(defs); err != nil {
+		return err
+	}
+	log.Printf("easymock: loaded %d routes from %s", len(defs), srv.dir)
+	return nil
+}
+
+func (srv *mockServer) watch(interval time.Duration) {
+	for range time.Tick(interval) {
+		srv.poll()
+	}
+}
+
+// poll reloads the definitions if a file has been added, removed or
+// modified since the last check.
+func (srv *mockServer) poll() {
+	snapshot, err := scanDefinitions(srv.dir)
+	if err != nil {
+		log.Printf("easymock: %v", err)
+		return
+	}
+	if sameSnapshot(snapshot, srv.snapshot) {
+		return
+	}
+	srv.snapshot = snapshot
+	if err := srv.reload(); err != nil {
+		log.Printf("easymock: reload failed, keeping previous routes:\n%v", err)
+	}
+}
+
+func scanDefinitions(dir string) (map[string]time.Time, error) {
+	snapshot := make(map[string]time.Time)
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			snapshot[path] = info.ModTime()
+		}
+		return nil
+	})
+	return snapshot, err
+}
+
+func sameSnapshot(a, b map[string]time.Time) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, modTime := range a {
+		if other, ok := b[path]; !ok || !other.Equal(modTime) {
+			return false
+		}
+	}
+	return true
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+func (sr *statusRecorder) Write(p []byte) (int, error) {
+	if sr.status == 0 {
+		sr.status = http.StatusOK
+	}
+	n, err := sr.ResponseWriter.Write(p)
+	sr.size += n
+	return n, err
+}
+
+func (sr *statusRecorder) Flush() {
+	if flusher, ok := sr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func logRequests(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			aborted := recover()
+			status := strings.TrimSpace(http.StatusText(rec.status))
+			if aborted != nil {
+				status = "aborted"
+			}
+			log.Printf("%s %s %d %s %dB %v", r.Method, r.URL.String(), rec.status, status, rec.size, time.Since(start))
+			if aborted != nil {
+				panic(aborted)
+			}
+		}()
+		next.ServeHTTP(rec, r)
+	})
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/SCU-SJL/easymock/client"
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const booksYAML = `routes:
+  - method: GET
+    url: https://api.easymock.com/books
+    response:
+      status: 200
+      body: %s
+`
+
+func writeBooks(t *testing.T, dir, body string) {
+	path := filepath.Join(dir, "books.yaml")
+	modTime := time.Now()
+	if info, err := os.Stat(path); err == nil && !modTime.After(info.ModTime()) {
+		// make sure the change is seen even on coarse mtime resolutions
+		modTime = info.ModTime().Add(time.Second)
+	}
+	assert.Nil(t, ioutil.WriteFile(path, []byte(fmt.Sprintf(booksYAML, body)), 0644))
+	assert.Nil(t, os.Chtimes(path, modTime, modTime))
+}
+
+func newTestServer(t *testing.T, admin bool) (*mockServer, string) {
+	dir, err := ioutil.TempDir("", "easymock")
+	assert.Nil(t, err)
+	writeBooks(t, dir, "books")
+	srv, err := newMockServer(dir, "https://api.easymock.com", admin)
+	if !assert.Nil(t, err) {
+		t.FailNow()
+	}
+	return srv, dir
+}
+
+func get(t *testing.T, url string) (int, string) {
+	resp, err := http.Get(url)
+	if !assert.Nil(t, err) {
+		return 0, ""
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	return resp.StatusCode, string(body)
+}
+
+func TestScanDefinitions(t *testing.T) {
+	dir, err := ioutil.TempDir("", "easymock")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "nested"), 0755))
+	writeBooks(t, dir, "books")
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "nested", "orders.json"), []byte(`{"routes": []}`), 0644))
+
+	first, err := scanDefinitions(dir)
+	assert.Nil(t, err)
+	assert.Len(t, first, 2)
+	second, err := scanDefinitions(dir)
+	assert.Nil(t, err)
+	assert.True(t, sameSnapshot(first, second))
+
+	writeBooks(t, dir, "changed")
+	modified, err := scanDefinitions(dir)
+	assert.Nil(t, err)
+	assert.False(t, sameSnapshot(first, modified))
+
+	assert.Nil(t, os.Remove(filepath.Join(dir, "nested", "orders.json")))
+	removed, err := scanDefinitions(dir)
+	assert.Nil(t, err)
+	assert.False(t, sameSnapshot(modified, removed))
+
+	_, err = scanDefinitions(filepath.Join(dir, "missing"))
+	assert.NotNil(t, err)
+}
+
+func TestReloadKeepsPreviousRoutesOnInvalidDefinitions(t *testing.T) {
+	srv, dir := newTestServer(t, false)
+	defer os.RemoveAll(dir)
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	writeBooks(t, dir, "new books")
+	srv.poll()
+	status, body := get(t, server.URL+"/books")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "new books", body)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "bad.yaml"), []byte("routes:\n  - method: GET\n"), 0644))
+	srv.poll()
+	status, body = get(t, server.URL+"/books")
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "new books", body)
+
+	assert.Nil(t, os.Remove(filepath.Join(dir, "bad.yaml")))
+	assert.Nil(t, os.Remove(filepath.Join(dir, "books.yaml")))
+	srv.poll()
+	status, _ = get(t, server.URL+"/books")
+	assert.Equal(t, http.StatusBadGateway, status)
+}
+
+func TestReloadKeepsAdminState(t *testing.T) {
+	srv, dir := newTestServer(t, true)
+	defer os.RemoveAll(dir)
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	c := client.New(server.URL)
+
+	_, err := c.Register(easymock.RouteDefinition{
+		ID:       "health",
+		Method:   http.MethodGet,
+		URL:      "https://api.easymock.com/health",
+		Response: easymock.ResponseDefinition{Status: http.StatusOK, Body: "ok"},
+	})
+	assert.Nil(t, err)
+	assert.Nil(t, c.SetScenarioState("cart", "HAS_ITEMS"))
+	get(t, server.URL+"/books")
+
+	writeBooks(t, dir, "new books")
+	srv.poll()
+
+	_, body := get(t, server.URL+"/health")
+	assert.Equal(t, "ok", body)
+	_, body = get(t, server.URL+"/books")
+	assert.Equal(t, "new books", body)
+	counts, err := c.CallCounts()
+	assert.Nil(t, err)
+	assert.Equal(t, 3, counts.Total)
+	assert.Equal(t, 2, counts.Routes["GET https://api.easymock.com/books"])
+	scenarios, err := c.Scenarios()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"cart": "HAS_ITEMS"}, scenarios)
+}
+
+func TestAdminIsDisabledByDefault(t *testing.T) {
+	srv, dir := newTestServer(t, false)
+	defer os.RemoveAll(dir)
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	_, err := client.New(server.URL).Routes()
+	apiErr, ok := err.(*client.APIError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusBadGateway, apiErr.StatusCode)
+	}
+}
+
+func TestLogRequests(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+	srv, dir := newTestServer(t, false)
+	defer os.RemoveAll(dir)
+	handler := logRequests(srv)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/books", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	logged := buf.String()
+	assert.Contains(t, logged, "easymock: loaded 1 routes from "+dir)
+	assert.Regexp(t, `GET /books 200 OK 5B \S+`, logged)
+	assert.Regexp(t, `GET /missing 502 Bad Gateway \d+B`, logged)
+}
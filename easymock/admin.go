@@ -0,0 +1,382 @@
+package easymock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const AdminPrefix = "/__easymock/"
+
+type RouteInfo struct {
+	ID       string   `json:"id"`
+	Kind     string   `json:"kind"`
+	Method   string   `json:"method"`
+	URL      string   `json:"url"`
+	Query    string   `json:"query,omitempty"`
+	Priority int      `json:"priority,omitempty"`
+	Enabled  bool     `json:"enabled"`
+	Matchers []string `json:"matchers,omitempty"`
+	Uses     int      `json:"uses"`
+	Calls    int      `json:"calls"`
+	Source   string   `json:"source,omitempty"`
+
+	Scenario      string `json:"scenario,omitempty"`
+	RequiredState string `json:"required_state,omitempty"`
+	NewState      string `json:"new_state,omitempty"`
+}
+
+type CallCounts struct {
+	Total     int            `json:"total"`
+	Unmatched int            `json:"unmatched"`
+	Routes    map[string]int `json:"routes"`
+}
+
+type JournalRecord struct {
+	Method     string      `json:"method"`
+	URL        string      `json:"url"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	Route      string      `json:"route,omitempty"`
+	Matched    bool        `json:"matched"`
+	StatusCode int         `json:"status,omitempty"`
+	RespHeader http.Header `json:"resp_header,omitempty"`
+	RespBody   string      `json:"resp_body,omitempty"`
+	Err        string      `json:"error,omitempty"`
+	Time       time.Time   `json:"time"`
+	Duration   string      `json:"duration"`
+}
+
+func (mocker *EasyMocker) RouteInfos() []RouteInfo {
+	mocker.responderMu.Lock()
+	routes := make([]*route, len(mocker.routes))
+	copy(routes, mocker.routes)
+	mocker.responderMu.Unlock()
+
+	infos := make([]RouteInfo, 0, len(routes))
+	for _, r := range routes {
+		infos = append(infos, mocker.routeInfo(r))
+	}
+	return infos
+}
+
+func (mocker *EasyMocker) RouteInfo(id string) (RouteInfo, bool) {
+	r, ok := mocker.findRouteByID(id)
+	if !ok {
+		return RouteInfo{}, false
+	}
+	return mocker.routeInfo(r), true
+}
+
+func (mocker *EasyMocker) routeInfo(r *route) RouteInfo {
+	info := RouteInfo{
+		ID:      r.id,
+		Kind:    r.key.kind.String(),
+		Method:  r.key.rt.Method,
+		URL:     r.key.rt.Url,
+		Enabled: r.responder.IsAvailable(),
+		Uses:    r.responder.Uses(),
+		Calls:   mocker.callCountOf(r.key),
+		Source:  r.source,
+	}
+	if r.key.kind == exactRouteKind {
+		info.Query = r.mode.String()
+	}
+	if r.regexResponder != nil {
+		info.Priority = r.regexResponder.Priority()
+	}
+	info.Scenario, info.RequiredState, info.NewState = r.responder.scenarioStates()
+	for _, m := range r.responder.Matchers() {
+		info.Matchers = append(info.Matchers, m.String())
+	}
+	return info
+}
+
+// EnableRoute and DisableRoute switch the responder of a route, which is
+// shared with any other route it was registered on.
+func (mocker *EasyMocker) EnableRoute(id string) bool {
+	r, ok := mocker.findRouteByID(id)
+	if ok {
+		r.responder.Enable()
+	}
+	return ok
+}
+
+func (mocker *EasyMocker) DisableRoute(id string) bool {
+	r, ok := mocker.findRouteByID(id)
+	if ok {
+		r.responder.Disable()
+	}
+	return ok
+}
+
+func (mocker *EasyMocker) CallCounts() CallCounts {
+	counts := CallCounts{
+		Total:     mocker.TotalCallCount(),
+		Unmatched: mocker.UnmatchedCallCount(),
+		Routes:    make(map[string]int),
+	}
+	mocker.matchCntMu.Lock()
+	for key, count := range mocker.matchedCounter {
+		counts.Routes[key.String()] = count
+	}
+	mocker.matchCntMu.Unlock()
+	return counts
+}
+
+func (entry *JournalEntry) record() JournalRecord {
+	rec := JournalRecord{
+		Method:     entry.Method,
+		URL:        entry.URL,
+		Header:     entry.Header,
+		Body:       string(entry.Body),
+		Route:      entry.Route,
+		Matched:    entry.Matched,
+		StatusCode: entry.StatusCode,
+		RespHeader: entry.RespHeader,
+		RespBody:   string(entry.RespBody),
+		Time:       entry.Time,
+		Duration:   entry.Duration.String(),
+	}
+	if entry.Err != nil {
+		rec.Err = entry.Err.Error()
+	}
+	return rec
+}
+
+// AdminHandler serves the admin api, which ServeHTTP and Handler also
+// expose under AdminPrefix once EnableAdmin has been called:
+//
+//	GET    routes              list routes
+//	POST   routes              register a RouteDefinition or a list of them
+//	GET    routes/{id}         show a route
+//	DELETE routes/{id}         remove a route
+//	POST   routes/{id}/enable  enable a route
+//	POST   routes/{id}/disable disable a route
+//	GET    counts              call counts
+//	GET    journal             request journal, ?unmatched=true for misses only
+//	DELETE journal             clear the journal
+//	GET    scenarios           states of the scenarios that left their initial state
+//	PUT    scenarios/{name}    set the state of a scenario, {"state": "..."}
+//	DELETE scenarios           move every scenario back to its initial state
+//	POST   reset               remove all routes, counts, scenarios and the journal
+func (mocker *EasyMocker) AdminHandler() http.Handler {
+	return http.HandlerFunc(mocker.serveAdmin)
+}
+
+// EnableAdmin exposes the admin api on ServeHTTP and Handler. Anyone who can
+// reach the server may then change its routes, so keep it on trusted hosts.
+func (mocker *EasyMocker) EnableAdmin() {
+	mocker.responderMu.Lock()
+	mocker.admin = true
+	mocker.responderMu.Unlock()
+}
+
+func (mocker *EasyMocker) DisableAdmin() {
+	mocker.responderMu.Lock()
+	mocker.admin = false
+	mocker.responderMu.Unlock()
+}
+
+func (mocker *EasyMocker) adminEnabled() bool {
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+	return mocker.admin
+}
+
+func isAdminRequest(r *http.Request) bool {
+	return !r.URL.IsAbs() && strings.HasPrefix(r.URL.Path, AdminPrefix)
+}
+
+func (mocker *EasyMocker) serveAdmin(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, AdminPrefix), "/"), "/")
+	switch {
+	case parts[0] == "routes" && len(parts) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			writeAdminJSON(w, http.StatusOK, mocker.RouteInfos())
+		case http.MethodPost:
+			mocker.adminCreateRoutes(w, r)
+		default:
+			adminMethodNotAllowed(w)
+		}
+	case parts[0] == "routes" && len(parts) == 2:
+		switch r.Method {
+		case http.MethodGet:
+			if info, ok := mocker.RouteInfo(parts[1]); ok {
+				writeAdminJSON(w, http.StatusOK, info)
+				return
+			}
+		case http.MethodDelete:
+			if mocker.RemoveRoute(parts[1]) {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+		default:
+			adminMethodNotAllowed(w)
+			return
+		}
+		adminRouteNotFound(w, parts[1])
+	case parts[0] == "routes" && len(parts) == 3 && (parts[2] == "enable" || parts[2] == "disable"):
+		if r.Method != http.MethodPost {
+			adminMethodNotAllowed(w)
+			return
+		}
+		toggle := mocker.EnableRoute
+		if parts[2] == "disable" {
+			toggle = mocker.DisableRoute
+		}
+		if !toggle(parts[1]) {
+			adminRouteNotFound(w, parts[1])
+			return
+		}
+		info, _ := mocker.RouteInfo(parts[1])
+		writeAdminJSON(w, http.StatusOK, info)
+	case parts[0] == "counts" && len(parts) == 1:
+		if r.Method != http.MethodGet {
+			adminMethodNotAllowed(w)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, mocker.CallCounts())
+	case parts[0] == "journal" && len(parts) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			entries := mocker.Journal()
+			if r.URL.Query().Get("unmatched") == "true" {
+				entries = mocker.UnmatchedRequests()
+			}
+			records := make([]JournalRecord, 0, len(entries))
+			for i := range entries {
+				records = append(records, entries[i].record())
+			}
+			writeAdminJSON(w, http.StatusOK, records)
+		case http.MethodDelete:
+			mocker.ResetJournal()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			adminMethodNotAllowed(w)
+		}
+	case parts[0] == "scenarios" && len(parts) == 1:
+		switch r.Method {
+		case http.MethodGet:
+			writeAdminJSON(w, http.StatusOK, mocker.Scenarios())
+		case http.MethodDelete:
+			mocker.ResetScenarios()
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			adminMethodNotAllowed(w)
+		}
+	case parts[0] == "scenarios" && len(parts) == 2:
+		if r.Method != http.MethodPut {
+			adminMethodNotAllowed(w)
+			return
+		}
+		var body struct {
+			State string `json:"state"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.State == "" {
+			writeAdminError(w, http.StatusBadRequest, `expected a body like {"state": "..."}`)
+			return
+		}
+		mocker.SetScenarioState(parts[1], body.State)
+		writeAdminJSON(w, http.StatusOK, map[string]string{"name": parts[1], "state": body.State})
+	case parts[0] == "reset" && len(parts) == 1:
+		if r.Method != http.MethodPost {
+			adminMethodNotAllowed(w)
+			return
+		}
+		mocker.Reset()
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeAdminError(w, http.StatusNotFound, fmt.Sprintf("unknown admin endpoint '%s'", r.URL.Path))
+	}
+}
+
+func (mocker *EasyMocker) adminCreateRoutes(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	defs := make([]RouteDefinition, 0)
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.DisallowUnknownFields()
+	trimmed := bytes.TrimSpace(body)
+	isList := len(trimmed) > 0 && trimmed[0] == '['
+	if isList {
+		err = dec.Decode(&defs)
+	} else {
+		var def RouteDefinition
+		err = dec.Decode(&def)
+		defs = append(defs, def)
+	}
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, strings.TrimPrefix(err.Error(), "json: "))
+		return
+	}
+
+	errs := make([]string, 0)
+	for _, def := range defs {
+		if def.Response.BodyFile != "" {
+			// never read files of the server on behalf of a remote caller
+			errs = append(errs, "response.body_file: files cannot be served through the admin api, send the body inline")
+			continue
+		}
+		if err := def.Validate(); err != nil {
+			errs = append(errs, err.(DefinitionErrors).messages()...)
+		}
+	}
+	if len(errs) > 0 {
+		writeAdminJSON(w, http.StatusBadRequest, map[string][]string{"errors": errs})
+		return
+	}
+
+	routes, err := mocker.registerDefinitions(defs)
+	if err != nil {
+		writeAdminJSON(w, http.StatusConflict, map[string][]string{"errors": err.(DefinitionErrors).messages()})
+		return
+	}
+	infos := make([]RouteInfo, 0, len(routes))
+	for _, r := range routes {
+		infos = append(infos, mocker.routeInfo(r))
+	}
+	if !isList {
+		writeAdminJSON(w, http.StatusCreated, infos[0])
+		return
+	}
+	writeAdminJSON(w, http.StatusCreated, infos)
+}
+
+func (errs DefinitionErrors) messages() []string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		if err.Path != "" {
+			msgs = append(msgs, err.Path+": "+err.Msg)
+		} else {
+			msgs = append(msgs, err.Msg)
+		}
+	}
+	return msgs
+}
+
+func adminRouteNotFound(w http.ResponseWriter, id string) {
+	writeAdminError(w, http.StatusNotFound, fmt.Sprintf("route '%s' not found", id))
+}
+
+func adminMethodNotAllowed(w http.ResponseWriter) {
+	writeAdminError(w, http.StatusMethodNotAllowed, "method not allowed")
+}
+
+func writeAdminError(w http.ResponseWriter, status int, msg string) {
+	writeAdminJSON(w, status, map[string]string{"error": msg})
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,104 @@
+package easymock
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type ChaosConfig struct {
+	FailureRate   float64
+	Err           error
+	StatusCode    int
+	LatencyRate   float64
+	Latency       time.Duration
+	LatencyJitter time.Duration
+	// Seed makes the injected failures reproducible, it is used as is
+	// unless RandomSeed asks for a time based seed.
+	Seed       int64
+	RandomSeed bool
+}
+
+type chaos struct {
+	mu   sync.Mutex
+	cfg  ChaosConfig
+	seed int64
+	rnd  *rand.Rand
+}
+
+func newChaos(cfg ChaosConfig) *chaos {
+	seed := cfg.Seed
+	if cfg.RandomSeed {
+		seed = time.Now().UnixNano()
+	}
+	if cfg.Err == nil && cfg.StatusCode == 0 {
+		cfg.StatusCode = http.StatusServiceUnavailable
+	}
+	return &chaos{
+		cfg:  cfg,
+		seed: seed,
+		rnd:  rand.New(rand.NewSource(seed)),
+	}
+}
+
+func (c *chaos) apply(req *http.Request, next RequestHandler) (*http.Response, error) {
+	c.mu.Lock()
+	fail := c.rnd.Float64() < c.cfg.FailureRate
+	slow := c.rnd.Float64() < c.cfg.LatencyRate
+	latency := c.cfg.Latency
+	if c.cfg.LatencyJitter > 0 {
+		latency += time.Duration(c.rnd.Int63n(int64(c.cfg.LatencyJitter)))
+	}
+	c.mu.Unlock()
+
+	if slow {
+		if err := sleepWithContext(req.Context(), latency); err != nil {
+			return nil, err
+		}
+	}
+	if !fail {
+		return next(req)
+	}
+	if c.cfg.Err != nil {
+		return nil, c.cfg.Err
+	}
+	resp := NewHttpResponseWithString(c.cfg.StatusCode, http.StatusText(c.cfg.StatusCode))
+	resp.Request = req
+	return resp, nil
+}
+
+func (mocker *EasyMocker) EnableChaos(cfg ChaosConfig) {
+	mocker.responderMu.Lock()
+	mocker.chaos = newChaos(cfg)
+	mocker.responderMu.Unlock()
+}
+
+// ChaosSeed returns the seed chaos is running with, so that a run with a
+// random seed can be reproduced.
+func (mocker *EasyMocker) ChaosSeed() (int64, bool) {
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+	if mocker.chaos == nil {
+		return 0, false
+	}
+	return mocker.chaos.seed, true
+}
+
+func (mocker *EasyMocker) DisableChaos() {
+	mocker.responderMu.Lock()
+	mocker.chaos = nil
+	mocker.responderMu.Unlock()
+}
+
+func (eR *EasyResponder) WithChaos(cfg ChaosConfig) *EasyResponder {
+	eR.mu.Lock()
+	eR.chaos = newChaos(cfg)
+	eR.mu.Unlock()
+	return eR
+}
+
+func (eRR *EasyRegexResponder) WithChaos(cfg ChaosConfig) *EasyRegexResponder {
+	eRR.EasyResponder.WithChaos(cfg)
+	return eRR
+}
@@ -0,0 +1,1027 @@
+package easymock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type MockDefinition struct {
+	Routes []RouteDefinition `json:"routes" yaml:"routes"`
+}
+
+type RouteDefinition struct {
+	ID           string              `json:"id,omitempty" yaml:"id,omitempty"`
+	Method       string              `json:"method" yaml:"method"`
+	URL          string              `json:"url,omitempty" yaml:"url,omitempty"`
+	Template     string              `json:"template,omitempty" yaml:"template,omitempty"`
+	Pattern      string              `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Query        string              `json:"query,omitempty" yaml:"query,omitempty"`
+	Priority     int                 `json:"priority,omitempty" yaml:"priority,omitempty"`
+	Matchers     []MatcherDefinition `json:"matchers,omitempty" yaml:"matchers,omitempty"`
+	Response     ResponseDefinition  `json:"response" yaml:"response"`
+	Delay        string              `json:"delay,omitempty" yaml:"delay,omitempty"`
+	DelayMax     string              `json:"delay_max,omitempty" yaml:"delay_max,omitempty"`
+	Times        int                 `json:"times,omitempty" yaml:"times,omitempty"`
+	ExpiresAfter string              `json:"expires_after,omitempty" yaml:"expires_after,omitempty"`
+	Disabled     bool                `json:"disabled,omitempty" yaml:"disabled,omitempty"`
+
+	Scenario      string `json:"scenario,omitempty" yaml:"scenario,omitempty"`
+	RequiredState string `json:"required_state,omitempty" yaml:"required_state,omitempty"`
+	NewState      string `json:"new_state,omitempty" yaml:"new_state,omitempty"`
+
+	file string
+	line int
+}
+
+type ResponseDefinition struct {
+	Status   int               `json:"status" yaml:"status"`
+	Headers  map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	Body     string            `json:"body,omitempty" yaml:"body,omitempty"`
+	JSONBody interface{}       `json:"json_body,omitempty" yaml:"json_body,omitempty"`
+	BodyFile string            `json:"body_file,omitempty" yaml:"body_file,omitempty"`
+	Template bool              `json:"template,omitempty" yaml:"template,omitempty"`
+}
+
+type MatcherDefinition struct {
+	Type     string              `json:"type" yaml:"type"`
+	Name     string              `json:"name,omitempty" yaml:"name,omitempty"`
+	Value    interface{}         `json:"value,omitempty" yaml:"value,omitempty"`
+	Pattern  string              `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Path     string              `json:"path,omitempty" yaml:"path,omitempty"`
+	Username string              `json:"username,omitempty" yaml:"username,omitempty"`
+	Password string              `json:"password,omitempty" yaml:"password,omitempty"`
+	Matchers []MatcherDefinition `json:"matchers,omitempty" yaml:"matchers,omitempty"`
+}
+
+type DefinitionError struct {
+	File string
+	Line int
+	Path string
+	Msg  string
+}
+
+func (e *DefinitionError) Error() string {
+	location := e.File
+	if location == "" {
+		location = "<definition>"
+	}
+	if e.Line > 0 {
+		location += ":" + strconv.Itoa(e.Line)
+	}
+	if e.Path != "" {
+		return location + ": " + e.Path + ": " + e.Msg
+	}
+	return location + ": " + e.Msg
+}
+
+type DefinitionErrors []*DefinitionError
+
+func (errs DefinitionErrors) Error() string {
+	msgs := make([]string, 0, len(errs))
+	for _, err := range errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+type fieldError struct {
+	path []interface{}
+	msg  string
+}
+
+func (fe fieldError) pathString() string {
+	var b strings.Builder
+	for _, p := range fe.path {
+		switch v := p.(type) {
+		case int:
+			b.WriteString("[" + strconv.Itoa(v) + "]")
+		default:
+			if b.Len() > 0 {
+				b.WriteString(".")
+			}
+			b.WriteString(fmt.Sprint(v))
+		}
+	}
+	return b.String()
+}
+
+func fieldErr(msg string, path ...interface{}) fieldError {
+	return fieldError{path: path, msg: msg}
+}
+
+func (def *RouteDefinition) Source() string {
+	if def.file == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s:%d", def.file, def.line)
+}
+
+func (def *RouteDefinition) Validate() error {
+	errs := def.validate()
+	if len(errs) == 0 {
+		return nil
+	}
+	return def.definitionErrors(errs, nil)
+}
+
+func (def *RouteDefinition) definitionErrors(errs []fieldError, lineOf func(path []interface{}) int) DefinitionErrors {
+	result := make(DefinitionErrors, 0, len(errs))
+	for _, fe := range errs {
+		line := def.line
+		if lineOf != nil {
+			line = lineOf(fe.path)
+		}
+		result = append(result, &DefinitionError{File: def.file, Line: line, Path: fe.pathString(), Msg: fe.msg})
+	}
+	return result
+}
+
+func (def *RouteDefinition) validate() []fieldError {
+	errs := make([]fieldError, 0)
+	if def.Method == "" {
+		errs = append(errs, fieldErr("is required", "method"))
+	}
+
+	targets := 0
+	for _, target := range []string{def.URL, def.Template, def.Pattern} {
+		if target != "" {
+			targets++
+		}
+	}
+	if targets != 1 {
+		errs = append(errs, fieldErr("exactly one of url, template or pattern must be set"))
+	}
+	if def.URL != "" {
+		if _, _, err := parseAndNormalizeUrl(def.URL); err != nil {
+			errs = append(errs, fieldErr(err.Error(), "url"))
+		}
+	}
+	if def.Template != "" {
+		if _, _, err := compileTemplate(def.Template); err != nil {
+			errs = append(errs, fieldErr(err.Error(), "template"))
+		}
+	}
+	if def.Pattern != "" {
+		if _, err := regexp.Compile(def.Pattern); err != nil {
+			errs = append(errs, fieldErr(err.Error(), "pattern"))
+		}
+	}
+	if _, err := parseQueryMatch(def.Query); err != nil {
+		errs = append(errs, fieldErr(err.Error(), "query"))
+	} else if def.Query != "" && def.URL == "" {
+		errs = append(errs, fieldErr("is only supported together with url", "query"))
+	}
+	if def.Priority != 0 && def.Pattern == "" {
+		errs = append(errs, fieldErr("is only supported together with pattern", "priority"))
+	}
+
+	for i := range def.Matchers {
+		errs = append(errs, def.Matchers[i].validate([]interface{}{"matchers", i})...)
+	}
+	errs = append(errs, def.Response.validate()...)
+
+	delay, err := parseOptionalDuration(def.Delay)
+	if err != nil {
+		errs = append(errs, fieldErr(err.Error(), "delay"))
+	}
+	if def.DelayMax != "" {
+		delayMax, err := parseOptionalDuration(def.DelayMax)
+		if err != nil {
+			errs = append(errs, fieldErr(err.Error(), "delay_max"))
+		} else if delayMax < delay {
+			errs = append(errs, fieldErr("must not be shorter than delay", "delay_max"))
+		}
+	}
+	if def.Times < 0 {
+		errs = append(errs, fieldErr("must not be negative", "times"))
+	}
+	if _, err := parseOptionalDuration(def.ExpiresAfter); err != nil {
+		errs = append(errs, fieldErr(err.Error(), "expires_after"))
+	}
+	if def.Scenario == "" {
+		if def.RequiredState != "" {
+			errs = append(errs, fieldErr("is only supported together with scenario", "required_state"))
+		}
+		if def.NewState != "" {
+			errs = append(errs, fieldErr("is only supported together with scenario", "new_state"))
+		}
+	}
+	return errs
+}
+
+func (resp *ResponseDefinition) validate() []fieldError {
+	errs := make([]fieldError, 0)
+	if resp.Status < 100 || resp.Status > 599 {
+		errs = append(errs, fieldErr(fmt.Sprintf("%d is not a valid http status", resp.Status), "response", "status"))
+	}
+	bodies := 0
+	if resp.Body != "" {
+		bodies++
+	}
+	if resp.JSONBody != nil {
+		bodies++
+	}
+	if resp.BodyFile != "" {
+		bodies++
+	}
+	if bodies > 1 {
+		errs = append(errs, fieldErr("only one of body, json_body or body_file may be set", "response"))
+	}
+	if resp.Template {
+		errs = append(errs, resp.validateTemplates()...)
+	}
+	return errs
+}
+
+func (resp *ResponseDefinition) validateTemplates() []fieldError {
+	errs := make([]fieldError, 0)
+	if resp.JSONBody != nil {
+		errs = append(errs, fieldErr("json_body cannot be a template, use body instead", "response", "template"))
+	}
+	if _, err := parseResponseTemplate("body", resp.Body); err != nil {
+		errs = append(errs, fieldErr(err.Error(), "response", "body"))
+	}
+	names := make([]string, 0, len(resp.Headers))
+	for name := range resp.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := parseResponseTemplate(name, resp.Headers[name]); err != nil {
+			errs = append(errs, fieldErr(err.Error(), "response", "headers", name))
+		}
+	}
+	return errs
+}
+
+var matcherFields = map[string][]string{
+	"host_equals":     {"value"},
+	"header_present":  {"name"},
+	"header_equals":   {"name", "value"},
+	"header_contains": {"name", "value"},
+	"header_matches":  {"name", "pattern"},
+	"query_present":   {"name"},
+	"query_equals":    {"name", "value"},
+	"cookie_present":  {"name"},
+	"cookie_equals":   {"name", "value"},
+	"basic_auth":      {"username"},
+	"bearer_token":    {"value"},
+	"form_equals":     {"name", "value"},
+	"body_matches":    {"pattern"},
+	"json_present":    {"path"},
+	"json_equals":     {"path", "value"},
+	"and":             {"matchers"},
+	"or":              {"matchers"},
+	"not":             {"matchers"},
+}
+
+func (md *MatcherDefinition) validate(path []interface{}) []fieldError {
+	at := func(field ...interface{}) []interface{} {
+		p := make([]interface{}, 0, len(path)+len(field))
+		return append(append(p, path...), field...)
+	}
+
+	required, ok := matcherFields[md.Type]
+	if !ok {
+		return []fieldError{{path: at("type"), msg: fmt.Sprintf("unknown matcher type '%s'", md.Type)}}
+	}
+	errs := make([]fieldError, 0)
+	for _, field := range required {
+		missing := false
+		switch field {
+		case "name":
+			missing = md.Name == ""
+		case "value":
+			missing = md.Value == nil
+		case "pattern":
+			missing = md.Pattern == ""
+		case "path":
+			missing = md.Path == ""
+		case "username":
+			missing = md.Username == ""
+		case "matchers":
+			missing = len(md.Matchers) == 0
+		}
+		if missing {
+			errs = append(errs, fieldError{path: at(field), msg: fmt.Sprintf("is required by '%s'", md.Type)})
+		}
+	}
+	if md.Type == "not" && len(md.Matchers) > 1 {
+		errs = append(errs, fieldError{path: at("matchers"), msg: "'not' takes exactly one matcher"})
+	}
+	if md.Pattern != "" {
+		if _, err := regexp.Compile(md.Pattern); err != nil {
+			errs = append(errs, fieldError{path: at("pattern"), msg: err.Error()})
+		}
+	}
+	if md.Value != nil && md.Type != "json_equals" {
+		if _, ok := scalarString(md.Value); !ok {
+			errs = append(errs, fieldError{path: at("value"), msg: "must be a string, number or boolean"})
+		}
+	}
+	for i := range md.Matchers {
+		errs = append(errs, md.Matchers[i].validate(at("matchers", i))...)
+	}
+	return errs
+}
+
+func (md *MatcherDefinition) Matcher() (Matcher, error) {
+	if errs := md.validate(nil); len(errs) > 0 {
+		return nil, fmt.Errorf("%s: %s", errs[0].pathString(), errs[0].msg)
+	}
+	return md.build(), nil
+}
+
+func (md *MatcherDefinition) build() Matcher {
+	value, _ := scalarString(md.Value)
+	children := make([]Matcher, 0, len(md.Matchers))
+	for i := range md.Matchers {
+		children = append(children, md.Matchers[i].build())
+	}
+
+	switch md.Type {
+	case "host_equals":
+		return HostEquals(value)
+	case "header_present":
+		return HeaderPresent(md.Name)
+	case "header_equals":
+		return HeaderEquals(md.Name, value)
+	case "header_contains":
+		return HeaderContains(md.Name, value)
+	case "header_matches":
+		return HeaderMatches(md.Name, md.Pattern)
+	case "query_present":
+		return QueryPresent(md.Name)
+	case "query_equals":
+		return QueryEquals(md.Name, value)
+	case "cookie_present":
+		return CookiePresent(md.Name)
+	case "cookie_equals":
+		return CookieEquals(md.Name, value)
+	case "basic_auth":
+		return BasicAuthEquals(md.Username, md.Password)
+	case "bearer_token":
+		return BearerTokenEquals(value)
+	case "form_equals":
+		return FormFieldEquals(md.Name, value)
+	case "body_matches":
+		return BodyMatches(md.Pattern)
+	case "json_present":
+		return JSONBodyFieldPresent(md.Path)
+	case "json_equals":
+		return JSONBodyFieldEquals(md.Path, normalizeYAMLValue(md.Value))
+	case "and":
+		return And(children...)
+	case "or":
+		return Or(children...)
+	}
+	return Not(children[0])
+}
+
+func scalarString(v interface{}) (string, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(s), true
+	}
+	return "", false
+}
+
+func parseQueryMatch(query string) (QueryMatch, error) {
+	switch query {
+	case "", "exact":
+		return QueryExact, nil
+	case "subset":
+		return QuerySubset, nil
+	case "ignore":
+		return QueryIgnore, nil
+	}
+	return QueryExact, fmt.Errorf("'%s' is not one of exact, subset or ignore", query)
+}
+
+func parseOptionalDuration(s string) (time.Duration, error) {
+	if s == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a valid duration", s)
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("'%s' must not be negative", s)
+	}
+	return d, nil
+}
+
+// Responder builds the responder described by the definition. Relative
+// body files are resolved against the directory of the definition file.
+// Template responses are rendered per request, see TemplateData.
+func (def *RouteDefinition) Responder() (*EasyResponder, error) {
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	resp, err := def.Response.httpResponse(filepath.Dir(def.file))
+	if err != nil {
+		return nil, def.definitionErrors([]fieldError{fieldErr(err.Error(), "response")}, nil)
+	}
+	var responder *EasyResponder
+	if def.Response.Template {
+		handler, err := templateRespHandler(resp)
+		if err != nil {
+			return nil, def.definitionErrors([]fieldError{fieldErr(err.Error(), "response")}, nil)
+		}
+		responder = NewEasyResponderWithReqHandler(handler)
+	} else {
+		responder = NewEasyResponderWithResp(resp)
+	}
+
+	if len(def.Matchers) > 0 {
+		matchers := make([]Matcher, 0, len(def.Matchers))
+		for i := range def.Matchers {
+			matchers = append(matchers, def.Matchers[i].build())
+		}
+		responder.When(matchers...)
+	}
+	def.applyOptions(responder)
+	return responder, nil
+}
+
+func (def *RouteDefinition) applyOptions(responder *EasyResponder) {
+	delay, _ := parseOptionalDuration(def.Delay)
+	if def.DelayMax != "" {
+		delayMax, _ := parseOptionalDuration(def.DelayMax)
+		responder.WithRandomDelay(delay, delayMax)
+	} else if delay > 0 {
+		responder.WithDelay(delay)
+	}
+	if def.Times > 0 {
+		responder.Times(def.Times)
+	}
+	if expiresAfter, _ := parseOptionalDuration(def.ExpiresAfter); expiresAfter > 0 {
+		responder.ExpireAfter(expiresAfter)
+	}
+	if def.Scenario != "" {
+		responder.InScenario(def.Scenario).WhenState(def.RequiredState).WillSetStateTo(def.NewState)
+	}
+	if def.Disabled {
+		responder.Disable()
+	}
+}
+
+func (resp *ResponseDefinition) httpResponse(baseDir string) (*http.Response, error) {
+	var body []byte
+	contentType := ""
+	switch {
+	case resp.BodyFile != "":
+		b, err := resp.readBodyFile(baseDir)
+		if err != nil {
+			return nil, err
+		}
+		body = b
+	case resp.JSONBody != nil:
+		jsonBody, err := toJSONValue(normalizeYAMLValue(resp.JSONBody))
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode json_body: %v", err)
+		}
+		b, err := json.Marshal(jsonBody)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode json_body: %v", err)
+		}
+		body = b
+		contentType = "application/json"
+	default:
+		body = []byte(resp.Body)
+	}
+
+	httpResp := NewHttpResponseWithBytes(resp.Status, body)
+	if contentType != "" {
+		httpResp.Header.Set("Content-Type", contentType)
+	}
+	for name, value := range resp.Headers {
+		httpResp.Header.Set(name, value)
+	}
+	return httpResp, nil
+}
+
+func (resp *ResponseDefinition) readBodyFile(baseDir string) ([]byte, error) {
+	path := resp.BodyFile
+	if !filepath.IsAbs(path) && baseDir != "" {
+		path = filepath.Join(baseDir, path)
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read body_file: %v", err)
+	}
+	return b, nil
+}
+
+// Inline returns a copy of the definition that no longer refers to files
+// next to its definition file, so it can be sent to a remote server.
+func (def *RouteDefinition) Inline() (RouteDefinition, error) {
+	inlined := *def
+	if def.Response.JSONBody != nil {
+		inlined.Response.JSONBody = normalizeYAMLValue(def.Response.JSONBody)
+	}
+	if def.Response.BodyFile == "" {
+		return inlined, nil
+	}
+	body, err := def.Response.readBodyFile(filepath.Dir(def.file))
+	if err != nil {
+		return inlined, err
+	}
+	if !utf8.Valid(body) {
+		return inlined, fmt.Errorf("body_file '%s' is binary and cannot be inlined", def.Response.BodyFile)
+	}
+	inlined.Response.Body = string(body)
+	inlined.Response.BodyFile = ""
+	return inlined, nil
+}
+
+func normalizeYAMLValue(v interface{}) interface{} {
+	switch node := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(node))
+		for k, child := range node {
+			m[fmt.Sprint(k)] = normalizeYAMLValue(child)
+		}
+		return m
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(node))
+		for k, child := range node {
+			m[k] = normalizeYAMLValue(child)
+		}
+		return m
+	case []interface{}:
+		s := make([]interface{}, 0, len(node))
+		for _, child := range node {
+			s = append(s, normalizeYAMLValue(child))
+		}
+		return s
+	}
+	return v
+}
+
+// RegisterDefinitions registers either all of defs or, if one of them is
+// invalid or conflicts with a registered route, none.
+func (mocker *EasyMocker) RegisterDefinitions(defs []RouteDefinition) error {
+	_, err := mocker.registerDefinitions(defs)
+	return err
+}
+
+func (mocker *EasyMocker) RegisterDefinition(def RouteDefinition) error {
+	_, err := mocker.registerDefinition(def)
+	return err
+}
+
+func (mocker *EasyMocker) registerDefinition(def RouteDefinition) (*route, error) {
+	r, err := def.route()
+	if err != nil {
+		return nil, err
+	}
+	if err := mocker.addRoute(r); err != nil {
+		return nil, def.definitionErrors([]fieldError{fieldErr(err.Error())}, nil)
+	}
+	return r, nil
+}
+
+func (def *RouteDefinition) route() (*route, error) {
+	responder, err := def.Responder()
+	if err != nil {
+		return nil, err
+	}
+	method := strings.ToUpper(def.Method)
+	var r *route
+	switch {
+	case def.URL != "":
+		mode, _ := parseQueryMatch(def.Query)
+		r, err = newExactRoute(method, def.URL, mode, responder)
+	case def.Template != "":
+		r, err = newTemplateRoute(method, def.Template, responder)
+	default:
+		regexResponder := &EasyRegexResponder{EasyResponder: responder}
+		regexResponder.SetPriority(def.Priority)
+		r, err = newRegexRoute(method, def.Pattern, regexResponder)
+	}
+	if err != nil {
+		return nil, def.definitionErrors([]fieldError{fieldErr(err.Error())}, nil)
+	}
+	r.id = def.ID
+	r.source = def.Source()
+	return r, nil
+}
+
+// definitionRoutes builds the routes of defs, reporting the errors of every
+// invalid definition at once.
+func definitionRoutes(defs []RouteDefinition) ([]*route, error) {
+	errs := make(DefinitionErrors, 0)
+	routes := make([]*route, 0, len(defs))
+	for i := range defs {
+		r, err := defs[i].route()
+		if err != nil {
+			if defErrs, ok := err.(DefinitionErrors); ok {
+				errs = append(errs, defErrs...)
+			} else {
+				errs = append(errs, &DefinitionError{File: defs[i].file, Line: defs[i].line, Msg: err.Error()})
+			}
+			continue
+		}
+		routes = append(routes, r)
+	}
+	if len(errs) > 0 {
+		return nil, errs
+	}
+	return routes, nil
+}
+
+// registerDefinitions registers either all of defs or none of them.
+func (mocker *EasyMocker) registerDefinitions(defs []RouteDefinition) ([]*route, error) {
+	routes, err := definitionRoutes(defs)
+	if err != nil {
+		return nil, err
+	}
+	if i, err := mocker.addRoutes(routes); err != nil {
+		return nil, defs[i].definitionErrors([]fieldError{fieldErr(err.Error())}, nil)
+	}
+	return routes, nil
+}
+
+// ReloadDefinitions replaces the routes that were loaded from definition
+// files with defs, usually the result of LoadDefinitions. Other routes, call
+// counts, the journal and scenario states are kept, and nothing changes if
+// one of defs cannot be registered.
+func (mocker *EasyMocker) ReloadDefinitions(defs []RouteDefinition) error {
+	routes, err := definitionRoutes(defs)
+	if err != nil {
+		return err
+	}
+
+	errs := make(DefinitionErrors, 0)
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+	previous, seq := mocker.routes, mocker.routeSeq
+	mocker.routes = make([]*route, 0, len(previous)+len(routes))
+	for _, r := range previous {
+		if r.source == "" {
+			mocker.routes = append(mocker.routes, r)
+		}
+	}
+	for i, r := range routes {
+		if err := mocker.addRouteLocked(r); err != nil {
+			errs = append(errs, defs[i].definitionErrors([]fieldError{fieldErr(err.Error())}, nil)...)
+		}
+	}
+	if len(errs) > 0 {
+		mocker.routes, mocker.routeSeq = previous, seq
+		return errs
+	}
+	return nil
+}
+
+func (mocker *EasyMocker) LoadDefinitions(path string) error {
+	defs, err := LoadDefinitions(path)
+	if err != nil {
+		return err
+	}
+	return mocker.RegisterDefinitions(defs)
+}
+
+var yamlLineRegex = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+
+func LoadDefinitions(path string) ([]RouteDefinition, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return ParseDefinitions(path, data)
+	}
+
+	files := make([]string, 0)
+	err = filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && isDefinitionFile(file) {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	defs := make([]RouteDefinition, 0)
+	errs := make(DefinitionErrors, 0)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileDefs, err := ParseDefinitions(file, data)
+		defs = append(defs, fileDefs...)
+		if err != nil {
+			errs = append(errs, err.(DefinitionErrors)...)
+		}
+	}
+	if len(errs) > 0 {
+		return defs, errs
+	}
+	return defs, nil
+}
+
+func isDefinitionFile(name string) bool {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json", ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// ParseDefinitions parses a definition file, choosing json or yaml by the
+// extension of name. Valid routes are returned even if others are invalid,
+// the returned error is then a DefinitionErrors.
+func ParseDefinitions(name string, data []byte) ([]RouteDefinition, error) {
+	if strings.ToLower(filepath.Ext(name)) == ".json" {
+		return parseJSONDefinitions(name, data)
+	}
+	return parseYAMLDefinitions(name, data)
+}
+
+func parseYAMLDefinitions(name string, data []byte) ([]RouteDefinition, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, DefinitionErrors{yamlError(name, 0, err.Error())}
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+
+	root := doc.Content[0]
+	routes := root
+	errs := make(DefinitionErrors, 0)
+	if root.Kind == yaml.MappingNode {
+		routes = nil
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if key := root.Content[i]; key.Value == "routes" {
+				routes = root.Content[i+1]
+			} else {
+				errs = append(errs, &DefinitionError{File: name, Line: key.Line, Path: key.Value, Msg: "unknown field"})
+			}
+		}
+	}
+	if routes == nil || routes.Tag == "!!null" {
+		return nil, errorsOrNil(errs)
+	}
+	if routes.Kind != yaml.SequenceNode {
+		return nil, append(errs, &DefinitionError{File: name, Line: routes.Line, Path: "routes", Msg: "must be a list"})
+	}
+
+	defs := make([]RouteDefinition, 0, len(routes.Content))
+	for i, node := range routes.Content {
+		def := RouteDefinition{file: name, line: node.Line}
+		var raw interface{}
+		if err := node.Decode(&raw); err != nil {
+			errs = append(errs, yamlError(name, node.Line, err.Error()))
+			continue
+		}
+		if fieldErrs := unknownFields(raw, reflect.TypeOf(def), nil); len(fieldErrs) > 0 {
+			errs = append(errs, routeErrors(i, def.definitionErrors(fieldErrs, yamlLineOf(node)))...)
+			continue
+		}
+		if err := node.Decode(&def); err != nil {
+			if typeErr, ok := err.(*yaml.TypeError); ok {
+				for _, msg := range typeErr.Errors {
+					errs = append(errs, yamlError(name, node.Line, msg))
+				}
+			} else {
+				errs = append(errs, yamlError(name, node.Line, err.Error()))
+			}
+			continue
+		}
+		if fieldErrs := def.validate(); len(fieldErrs) > 0 {
+			errs = append(errs, routeErrors(i, def.definitionErrors(fieldErrs, yamlLineOf(node)))...)
+			continue
+		}
+		defs = append(defs, def)
+	}
+	return defs, errorsOrNil(errs)
+}
+
+func yamlError(name string, line int, msg string) *DefinitionError {
+	if match := yamlLineRegex.FindStringSubmatch(msg); match != nil {
+		line, _ = strconv.Atoi(match[1])
+		msg = match[2]
+	}
+	return &DefinitionError{File: name, Line: line, Msg: strings.TrimPrefix(msg, "yaml: ")}
+}
+
+func yamlLineOf(node *yaml.Node) func(path []interface{}) int {
+	return func(path []interface{}) int {
+		line := node.Line
+		cur := node
+		for _, p := range path {
+			var next *yaml.Node
+			switch v := p.(type) {
+			case string:
+				if cur.Kind == yaml.MappingNode {
+					for i := 0; i+1 < len(cur.Content); i += 2 {
+						if cur.Content[i].Value == v {
+							next = cur.Content[i+1]
+						}
+					}
+				}
+			case int:
+				if cur.Kind == yaml.SequenceNode && v < len(cur.Content) {
+					next = cur.Content[v]
+				}
+			}
+			if next == nil {
+				break
+			}
+			cur = next
+			line = cur.Line
+		}
+		return line
+	}
+}
+
+func parseJSONDefinitions(name string, data []byte) ([]RouteDefinition, error) {
+	syntaxErr := func(err error, offset int64) error {
+		if se, ok := err.(*json.SyntaxError); ok {
+			offset = se.Offset
+		}
+		return DefinitionErrors{{File: name, Line: lineAt(data, offset), Msg: strings.TrimPrefix(err.Error(), "json: ")}}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, syntaxErr(err, dec.InputOffset())
+	}
+	errs := make(DefinitionErrors, 0)
+	defs := make([]RouteDefinition, 0)
+	parseRoutes := func() error {
+		for i := 0; dec.More(); i++ {
+			offset := skipJSONSpace(data, dec.InputOffset())
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return syntaxErr(err, offset)
+			}
+			def := RouteDefinition{file: name, line: lineAt(data, offset)}
+			var generic interface{}
+			_ = json.Unmarshal(raw, &generic)
+			if fieldErrs := unknownFields(generic, reflect.TypeOf(def), nil); len(fieldErrs) > 0 {
+				errs = append(errs, routeErrors(i, def.definitionErrors(fieldErrs, nil))...)
+				continue
+			}
+			if err := json.Unmarshal(raw, &def); err != nil {
+				msg := strings.TrimPrefix(err.Error(), "json: ")
+				errs = append(errs, routeErrors(i, DefinitionErrors{{File: name, Line: def.line, Msg: msg}})...)
+				continue
+			}
+			if fieldErrs := def.validate(); len(fieldErrs) > 0 {
+				errs = append(errs, routeErrors(i, def.definitionErrors(fieldErrs, nil))...)
+				continue
+			}
+			defs = append(defs, def)
+		}
+		// consume the closing bracket so that the remaining keys can be read
+		if _, err := dec.Token(); err != nil {
+			return syntaxErr(err, dec.InputOffset())
+		}
+		return nil
+	}
+
+	switch tok {
+	case json.Delim('['):
+		if err := parseRoutes(); err != nil {
+			return defs, append(errs, err.(DefinitionErrors)...)
+		}
+	case json.Delim('{'):
+		for dec.More() {
+			keyOffset := skipJSONSpace(data, dec.InputOffset())
+			key, err := dec.Token()
+			if err != nil {
+				return nil, syntaxErr(err, dec.InputOffset())
+			}
+			if key != "routes" {
+				errs = append(errs, &DefinitionError{File: name, Line: lineAt(data, keyOffset), Path: fmt.Sprint(key), Msg: "unknown field"})
+				var skip json.RawMessage
+				if err := dec.Decode(&skip); err != nil {
+					return nil, syntaxErr(err, dec.InputOffset())
+				}
+				continue
+			}
+			if tok, err = dec.Token(); err != nil {
+				return nil, syntaxErr(err, dec.InputOffset())
+			}
+			if tok != json.Delim('[') {
+				return nil, append(errs, &DefinitionError{File: name, Line: lineAt(data, keyOffset), Path: "routes", Msg: "must be a list"})
+			}
+			if err := parseRoutes(); err != nil {
+				return defs, append(errs, err.(DefinitionErrors)...)
+			}
+		}
+	default:
+		return nil, DefinitionErrors{{File: name, Line: 1, Msg: "expected an object with routes or a list of routes"}}
+	}
+	return defs, errorsOrNil(errs)
+}
+
+func skipJSONSpace(data []byte, offset int64) int64 {
+	for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,", data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+func lineAt(data []byte, offset int64) int {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	return bytes.Count(data[:offset], []byte("\n")) + 1
+}
+
+func routeErrors(index int, errs DefinitionErrors) DefinitionErrors {
+	prefix := "routes[" + strconv.Itoa(index) + "]"
+	for _, err := range errs {
+		if err.Path == "" {
+			err.Path = prefix
+		} else {
+			err.Path = prefix + "." + err.Path
+		}
+	}
+	return errs
+}
+
+func errorsOrNil(errs DefinitionErrors) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func unknownFields(value interface{}, t reflect.Type, path []interface{}) []fieldError {
+	at := func(field interface{}) []interface{} {
+		p := make([]interface{}, 0, len(path)+1)
+		return append(append(p, path...), field)
+	}
+
+	errs := make([]fieldError, 0)
+	switch t.Kind() {
+	case reflect.Struct:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return errs
+		}
+		fields := make(map[string]reflect.Type)
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if tag := strings.Split(field.Tag.Get("json"), ",")[0]; tag != "" {
+				fields[tag] = field.Type
+			}
+		}
+		for _, key := range sortedInterfaceKeys(m) {
+			fieldType, known := fields[key]
+			if !known {
+				errs = append(errs, fieldError{path: at(key), msg: "unknown field"})
+				continue
+			}
+			errs = append(errs, unknownFields(m[key], fieldType, at(key))...)
+		}
+	case reflect.Ptr:
+		errs = append(errs, unknownFields(value, t.Elem(), path)...)
+	case reflect.Map:
+		if m, ok := value.(map[string]interface{}); ok {
+			for _, key := range sortedInterfaceKeys(m) {
+				errs = append(errs, unknownFields(m[key], t.Elem(), at(key))...)
+			}
+		}
+	case reflect.Slice:
+		if s, ok := value.([]interface{}); ok {
+			for i, elem := range s {
+				errs = append(errs, unknownFields(elem, t.Elem(), at(i))...)
+			}
+		}
+	}
+	return errs
+}
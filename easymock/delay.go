@@ -0,0 +1,111 @@
+package easymock
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type DelayFunc func() time.Duration
+
+var (
+	delayRandMu sync.Mutex
+	delayRand   = rand.New(rand.NewSource(time.Now().UnixNano()))
+)
+
+func FixedDelay(d time.Duration) DelayFunc {
+	return func() time.Duration {
+		return d
+	}
+}
+
+func UniformDelay(min, max time.Duration) DelayFunc {
+	if max < min {
+		min, max = max, min
+	}
+	return func() time.Duration {
+		if max == min {
+			return min
+		}
+		delayRandMu.Lock()
+		defer delayRandMu.Unlock()
+		return min + time.Duration(delayRand.Int63n(int64(max-min)))
+	}
+}
+
+func NormalDelay(mean, stddev time.Duration) DelayFunc {
+	return func() time.Duration {
+		delayRandMu.Lock()
+		d := time.Duration(delayRand.NormFloat64()*float64(stddev)) + mean
+		delayRandMu.Unlock()
+		if d < 0 {
+			return 0
+		}
+		return d
+	}
+}
+
+func (eR *EasyResponder) WithDelay(d time.Duration) *EasyResponder {
+	return eR.WithDelayFunc(FixedDelay(d))
+}
+
+func (eR *EasyResponder) WithRandomDelay(min, max time.Duration) *EasyResponder {
+	return eR.WithDelayFunc(UniformDelay(min, max))
+}
+
+func (eR *EasyResponder) WithDelayFunc(delay DelayFunc) *EasyResponder {
+	eR.mu.Lock()
+	eR.delay = delay
+	eR.mu.Unlock()
+	return eR
+}
+
+func (eRR *EasyRegexResponder) WithDelay(d time.Duration) *EasyRegexResponder {
+	eRR.EasyResponder.WithDelay(d)
+	return eRR
+}
+
+func (eRR *EasyRegexResponder) WithRandomDelay(min, max time.Duration) *EasyRegexResponder {
+	eRR.EasyResponder.WithRandomDelay(min, max)
+	return eRR
+}
+
+func (eRR *EasyRegexResponder) WithDelayFunc(delay DelayFunc) *EasyRegexResponder {
+	eRR.EasyResponder.WithDelayFunc(delay)
+	return eRR
+}
+
+func (eR *EasyResponder) respond(req *http.Request) (*http.Response, error) {
+	eR.mu.Lock()
+	delay, routeChaos := eR.delay, eR.chaos
+	eR.mu.Unlock()
+
+	if delay != nil {
+		if err := sleepWithContext(req.Context(), delay()); err != nil {
+			return nil, err
+		}
+	}
+	if routeChaos != nil {
+		return routeChaos.apply(req, eR.reqHandler)
+	}
+	return eR.reqHandler(req)
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
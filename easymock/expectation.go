@@ -0,0 +1,148 @@
+package easymock
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+type CallExpectation struct {
+	min, max int
+	desc     string
+}
+
+func Exactly(n int) CallExpectation {
+	return CallExpectation{min: n, max: n, desc: fmt.Sprintf("exactly %d call(s)", n)}
+}
+
+func AtLeast(n int) CallExpectation {
+	return CallExpectation{min: n, max: -1, desc: fmt.Sprintf("at least %d call(s)", n)}
+}
+
+func AtMost(n int) CallExpectation {
+	return CallExpectation{min: 0, max: n, desc: fmt.Sprintf("at most %d call(s)", n)}
+}
+
+func Between(min, max int) CallExpectation {
+	return CallExpectation{min: min, max: max, desc: fmt.Sprintf("between %d and %d call(s)", min, max)}
+}
+
+func ExactlyOnce() CallExpectation {
+	return Exactly(1)
+}
+
+func AtLeastOnce() CallExpectation {
+	return AtLeast(1)
+}
+
+func Never() CallExpectation {
+	return Exactly(0)
+}
+
+func (ce CallExpectation) IsMet(calls int) bool {
+	return calls >= ce.min && (ce.max < 0 || calls <= ce.max)
+}
+
+func (ce CallExpectation) String() string {
+	return ce.desc
+}
+
+type routeExpectation struct {
+	key         routeKey
+	expectation CallExpectation
+}
+
+func (mocker *EasyMocker) CallCount(method, url string) int {
+	return mocker.callCountOf(mocker.exactRouteKey(method, url))
+}
+
+func (mocker *EasyMocker) RegexCallCount(method, pattern string) int {
+	return mocker.callCountOf(routeKey{kind: regexRouteKind, rt: router{Method: method, Url: pattern}})
+}
+
+func (mocker *EasyMocker) TemplateCallCount(method, template string) int {
+	return mocker.callCountOf(routeKey{kind: templateRouteKind, rt: router{Method: method, Url: template}})
+}
+
+func (mocker *EasyMocker) callCountOf(key routeKey) int {
+	mocker.matchCntMu.Lock()
+	defer mocker.matchCntMu.Unlock()
+	return mocker.matchedCounter[key]
+}
+
+func (mocker *EasyMocker) TotalCallCount() int {
+	mocker.missCntMu.Lock()
+	defer mocker.missCntMu.Unlock()
+	return mocker.totalCount
+}
+
+func (mocker *EasyMocker) UnmatchedCallCount() int {
+	mocker.missCntMu.Lock()
+	defer mocker.missCntMu.Unlock()
+	count := 0
+	for _, n := range mocker.mismatchCounter {
+		count += n
+	}
+	return count
+}
+
+func (mocker *EasyMocker) UnmatchedCalls() map[string]int {
+	mocker.missCntMu.Lock()
+	defer mocker.missCntMu.Unlock()
+	calls := make(map[string]int, len(mocker.mismatchCounter))
+	for rt, n := range mocker.mismatchCounter {
+		calls[rt.String()] = n
+	}
+	return calls
+}
+
+func (mocker *EasyMocker) ExpectCalls(method, url string, expectation CallExpectation) {
+	mocker.addExpectation(&routeExpectation{
+		key:         mocker.exactRouteKey(method, url),
+		expectation: expectation,
+	})
+}
+
+func (mocker *EasyMocker) ExpectRegexCalls(method, pattern string, expectation CallExpectation) {
+	mocker.addExpectation(&routeExpectation{
+		key:         routeKey{kind: regexRouteKind, rt: router{Method: method, Url: pattern}},
+		expectation: expectation,
+	})
+}
+
+func (mocker *EasyMocker) ExpectTemplateCalls(method, template string, expectation CallExpectation) {
+	mocker.addExpectation(&routeExpectation{
+		key:         routeKey{kind: templateRouteKind, rt: router{Method: method, Url: template}},
+		expectation: expectation,
+	})
+}
+
+func (mocker *EasyMocker) addExpectation(re *routeExpectation) {
+	mocker.matchCntMu.Lock()
+	mocker.expectations = append(mocker.expectations, re)
+	mocker.matchCntMu.Unlock()
+}
+
+func (mocker *EasyMocker) UnmetExpectations() []string {
+	mocker.matchCntMu.Lock()
+	defer mocker.matchCntMu.Unlock()
+
+	unmet := make([]string, 0)
+	for _, re := range mocker.expectations {
+		calls := mocker.matchedCounter[re.key]
+		if !re.expectation.IsMet(calls) {
+			unmet = append(unmet, fmt.Sprintf("%s: expected %s, got %d", re.key, re.expectation, calls))
+		}
+	}
+	return unmet
+}
+
+func (mocker *EasyMocker) AssertExpectations(t testing.TB) bool {
+	t.Helper()
+	unmet := mocker.UnmetExpectations()
+	if len(unmet) == 0 {
+		return true
+	}
+	t.Errorf("easymock: %d expectation(s) not met:\n  %s", len(unmet), strings.Join(unmet, "\n  "))
+	return false
+}
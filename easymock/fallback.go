@@ -0,0 +1,106 @@
+package easymock
+
+import (
+	"context"
+	"net/http"
+)
+
+type fallbackMode int
+
+const (
+	fallbackFail fallbackMode = iota
+	fallbackPassthrough
+	fallbackPassthroughHosts
+	fallbackRespond
+)
+
+type fallbackPolicy struct {
+	mode      fallbackMode
+	hosts     *StringSet
+	responder *EasyResponder
+}
+
+func (mocker *EasyMocker) FailUnmatched() {
+	mocker.setFallback(fallbackPolicy{mode: fallbackFail})
+}
+
+func (mocker *EasyMocker) PassthroughUnmatched() {
+	mocker.setFallback(fallbackPolicy{mode: fallbackPassthrough})
+}
+
+// PassthroughUnmatchedHosts forwards unmatched requests to the original
+// transport only when their host, with or without port, is in hosts.
+func (mocker *EasyMocker) PassthroughUnmatchedHosts(hosts ...string) {
+	mocker.setFallback(fallbackPolicy{mode: fallbackPassthroughHosts, hosts: CreateStringSet(hosts)})
+}
+
+// RespondUnmatched answers unmatched requests with responder, a nil
+// responder restores the default of failing them.
+func (mocker *EasyMocker) RespondUnmatched(responder *EasyResponder) {
+	if responder == nil {
+		mocker.FailUnmatched()
+		return
+	}
+	mocker.setFallback(fallbackPolicy{mode: fallbackRespond, responder: responder})
+}
+
+func (mocker *EasyMocker) setFallback(policy fallbackPolicy) {
+	mocker.responderMu.Lock()
+	mocker.fallback = policy
+	mocker.responderMu.Unlock()
+}
+
+func (mocker *EasyMocker) handleUnmatched(req *http.Request, rt router, entry *JournalEntry, disabled bool) (*http.Response, error) {
+	mocker.responderMu.Lock()
+	policy := mocker.fallback
+	mocker.responderMu.Unlock()
+
+	switch policy.mode {
+	case fallbackPassthrough:
+		return mocker.passthrough(req, entry)
+	case fallbackPassthroughHosts:
+		if policy.hosts.Contains(req.URL.Host) || policy.hosts.Contains(req.URL.Hostname()) {
+			return mocker.passthrough(req, entry)
+		}
+	case fallbackRespond:
+		entry.Route = "(default response)"
+		return policy.responder.respond(req)
+	}
+
+	mocker.reportUnmatched(req, rt.Method)
+	return mocker.connectFail(req, disabled)
+}
+
+func (mocker *EasyMocker) passthrough(req *http.Request, entry *JournalEntry) (*http.Response, error) {
+	entry.Route = "(passthrough)"
+	return mocker.origin(req).RoundTrip(req)
+}
+
+type originTransportKey struct{}
+
+// clientTransport is installed by StartWithClient so that passthrough keeps
+// using the transport the client had before, e.g. one trusting a test CA.
+type clientTransport struct {
+	mocker *EasyMocker
+	origin http.RoundTripper
+}
+
+func (ct *clientTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if ct.origin == nil {
+		return ct.mocker.RoundTrip(req)
+	}
+	return ct.mocker.RoundTrip(req.WithContext(context.WithValue(req.Context(), originTransportKey{}, ct.origin)))
+}
+
+func (mocker *EasyMocker) origin(req *http.Request) http.RoundTripper {
+	if origin, ok := req.Context().Value(originTransportKey{}).(http.RoundTripper); ok {
+		return origin
+	}
+	globalMu.Lock()
+	origin := mocker.originTransport
+	globalMu.Unlock()
+	if origin == nil || origin == http.RoundTripper(mocker) {
+		origin = OriginTransport
+	}
+	return origin
+}
@@ -0,0 +1,173 @@
+package easymock
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+type hostAddr string
+
+func (addr hostAddr) Network() string {
+	return "tcp"
+}
+
+func (addr hostAddr) String() string {
+	return string(addr)
+}
+
+type timeoutError struct {
+	op string
+}
+
+func (e *timeoutError) Error() string {
+	return e.op + ": i/o timeout"
+}
+
+func (e *timeoutError) Timeout() bool {
+	return true
+}
+
+func (e *timeoutError) Temporary() bool {
+	return true
+}
+
+func remoteAddr(req *http.Request) net.Addr {
+	host, port := req.URL.Hostname(), req.URL.Port()
+	if port == "" {
+		port = defaultPorts[req.URL.Scheme]
+	}
+	return hostAddr(net.JoinHostPort(host, port))
+}
+
+func NewConnRefusedEasyResponder() *EasyResponder {
+	return NewEasyResponderWithReqHandler(func(req *http.Request) (*http.Response, error) {
+		return nil, &net.OpError{
+			Op:   "dial",
+			Net:  "tcp",
+			Addr: remoteAddr(req),
+			Err:  os.NewSyscallError("connect", syscall.ECONNREFUSED),
+		}
+	})
+}
+
+func NewDNSErrorEasyResponder() *EasyResponder {
+	return NewEasyResponderWithReqHandler(func(req *http.Request) (*http.Response, error) {
+		return nil, &net.OpError{
+			Op:  "dial",
+			Net: "tcp",
+			Err: &net.DNSError{
+				Err:        "no such host",
+				Name:       req.URL.Hostname(),
+				IsNotFound: true,
+			},
+		}
+	})
+}
+
+func NewTimeoutEasyResponder() *EasyResponder {
+	return NewEasyResponderWithReqHandler(func(req *http.Request) (*http.Response, error) {
+		return nil, &net.OpError{
+			Op:   "dial",
+			Net:  "tcp",
+			Addr: remoteAddr(req),
+			Err:  &timeoutError{op: "dial"},
+		}
+	})
+}
+
+func NewTLSHandshakeErrorEasyResponder() *EasyResponder {
+	return NewEasyResponderWithReqHandler(func(req *http.Request) (*http.Response, error) {
+		return nil, &net.OpError{
+			Op:  "remote error",
+			Err: errors.New("tls: handshake failure"),
+		}
+	})
+}
+
+func NewConnResetEasyResponder(statusCode int, partialBody []byte) *EasyResponder {
+	return newFaultyBodyEasyResponder(statusCode, partialBody, connResetError)
+}
+
+func connResetError(req *http.Request) error {
+	return &net.OpError{
+		Op:   "read",
+		Net:  "tcp",
+		Addr: remoteAddr(req),
+		Err:  os.NewSyscallError("read", syscall.ECONNRESET),
+	}
+}
+
+func NewUnexpectedEOFEasyResponder(statusCode int, partialBody []byte) *EasyResponder {
+	return newFaultyBodyEasyResponder(statusCode, partialBody, func(req *http.Request) error {
+		return io.ErrUnexpectedEOF
+	})
+}
+
+func NewHangingBodyEasyResponder(statusCode int) *EasyResponder {
+	return NewEasyResponderWithReqHandler(func(req *http.Request) (*http.Response, error) {
+		resp := newHttpResponse(statusCode, nil)
+		resp.Body = &hangingBody{ctx: req.Context(), closed: make(chan struct{})}
+		resp.ContentLength = -1
+		resp.Request = req
+		return resp, nil
+	})
+}
+
+func newFaultyBodyEasyResponder(statusCode int, partialBody []byte, fault func(req *http.Request) error) *EasyResponder {
+	return NewEasyResponderWithReqHandler(func(req *http.Request) (*http.Response, error) {
+		resp := newHttpResponse(statusCode, nil)
+		resp.Body = &faultyBody{data: partialBody, err: fault(req)}
+		resp.ContentLength = int64(len(partialBody) + 1)
+		resp.Header.Set("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+		resp.Request = req
+		return resp, nil
+	})
+}
+
+type faultyBody struct {
+	data []byte
+	pos  int
+	err  error
+}
+
+func (fb *faultyBody) Read(p []byte) (int, error) {
+	if fb.pos >= len(fb.data) {
+		return 0, fb.err
+	}
+	n := copy(p, fb.data[fb.pos:])
+	fb.pos += n
+	return n, nil
+}
+
+func (fb *faultyBody) Close() error {
+	return nil
+}
+
+type hangingBody struct {
+	ctx       context.Context
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (hb *hangingBody) Read(p []byte) (int, error) {
+	select {
+	case <-hb.ctx.Done():
+		return 0, hb.ctx.Err()
+	case <-hb.closed:
+		return 0, errors.New("read on closed response body")
+	}
+}
+
+func (hb *hangingBody) Close() error {
+	hb.closeOnce.Do(func() {
+		close(hb.closed)
+	})
+	return nil
+}
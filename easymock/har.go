@@ -0,0 +1,240 @@
+package easymock
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type HAR struct {
+	Log HARLog `json:"log"`
+}
+
+type HARLog struct {
+	Version string     `json:"version"`
+	Creator HARCreator `json:"creator"`
+	Entries []HAREntry `json:"entries"`
+}
+
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+type HAREntry struct {
+	StartedDateTime string      `json:"startedDateTime"`
+	Time            float64     `json:"time"`
+	Request         HARRequest  `json:"request"`
+	Response        HARResponse `json:"response"`
+	Cache           struct{}    `json:"cache"`
+	Timings         HARTimings  `json:"timings"`
+}
+
+type HARRequest struct {
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	QueryString []HARNameValue `json:"queryString"`
+	PostData    *HARPostData   `json:"postData,omitempty"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARResponse struct {
+	Status      int            `json:"status"`
+	StatusText  string         `json:"statusText"`
+	HTTPVersion string         `json:"httpVersion"`
+	Cookies     []HARNameValue `json:"cookies"`
+	Headers     []HARNameValue `json:"headers"`
+	Content     HARContent     `json:"content"`
+	RedirectURL string         `json:"redirectURL"`
+	HeadersSize int            `json:"headersSize"`
+	BodySize    int            `json:"bodySize"`
+}
+
+type HARNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type HARPostData struct {
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+type HARContent struct {
+	Size     int    `json:"size"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text,omitempty"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+type HARTimings struct {
+	Send    float64 `json:"send"`
+	Wait    float64 `json:"wait"`
+	Receive float64 `json:"receive"`
+}
+
+func LoadHAR(path string) (*HAR, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	har := &HAR{}
+	if err := json.Unmarshal(b, har); err != nil {
+		return nil, fmt.Errorf("invalid har file '%s': %v", path, err)
+	}
+	return har, nil
+}
+
+func (har *HAR) Save(path string) error {
+	b, err := json.MarshalIndent(har, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// RegisterHAR registers a responder for every entry of the archive.
+// Entries that share a method and URL are answered in their recorded order.
+func (mocker *EasyMocker) RegisterHAR(har *HAR) error {
+	cassette := &Cassette{Interactions: make([]Interaction, 0, len(har.Log.Entries))}
+	for i, entry := range har.Log.Entries {
+		body, err := entry.Response.Content.decode()
+		if err != nil {
+			return fmt.Errorf("har entry %d: %v", i, err)
+		}
+		header := harHeader(entry.Response.Headers)
+		header.Del("Content-Encoding")
+		if header.Get("Content-Type") == "" && entry.Response.Content.MimeType != "" {
+			header.Set("Content-Type", entry.Response.Content.MimeType)
+		}
+		cassette.Interactions = append(cassette.Interactions, Interaction{
+			Request: RecordedRequest{
+				Method: entry.Request.Method,
+				URL:    entry.Request.URL,
+			},
+			Response: RecordedResponse{
+				StatusCode: entry.Response.Status,
+				Header:     header,
+				Body:       body,
+			},
+		})
+	}
+	return mocker.Replay(cassette, ReplayOptions{})
+}
+
+func (mocker *EasyMocker) ExportHAR() *HAR {
+	journal := mocker.Journal()
+	har := &HAR{Log: HARLog{
+		Version: "1.2",
+		Creator: HARCreator{Name: "easymock", Version: "1.0"},
+		Entries: make([]HAREntry, 0, len(journal)),
+	}}
+	for _, entry := range journal {
+		har.Log.Entries = append(har.Log.Entries, entry.toHAR())
+	}
+	return har
+}
+
+func (content HARContent) decode() ([]byte, error) {
+	if content.Encoding == "base64" {
+		return base64.StdEncoding.DecodeString(content.Text)
+	}
+	return []byte(content.Text), nil
+}
+
+func (entry JournalEntry) toHAR() HAREntry {
+	req := HARRequest{
+		Method:      entry.Method,
+		URL:         entry.URL,
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     make([]HARNameValue, 0),
+		Headers:     harNameValues(entry.Header),
+		QueryString: make([]HARNameValue, 0),
+		HeadersSize: -1,
+		BodySize:    len(entry.Body),
+	}
+	if u, err := url.Parse(entry.URL); err == nil {
+		req.QueryString = harNameValues(u.Query())
+	}
+	if len(entry.Body) > 0 {
+		req.PostData = &HARPostData{
+			MimeType: entry.Header.Get("Content-Type"),
+			Text:     string(entry.Body),
+		}
+	}
+
+	resp := HARResponse{
+		Status:      entry.StatusCode,
+		StatusText:  http.StatusText(entry.StatusCode),
+		HTTPVersion: "HTTP/1.1",
+		Cookies:     make([]HARNameValue, 0),
+		Headers:     harNameValues(entry.RespHeader),
+		Content: HARContent{
+			Size:     len(entry.RespBody),
+			MimeType: entry.RespHeader.Get("Content-Type"),
+		},
+		HeadersSize: -1,
+		BodySize:    len(entry.RespBody),
+	}
+	if isTextContent(resp.Content.MimeType, entry.RespBody) {
+		resp.Content.Text = string(entry.RespBody)
+	} else if len(entry.RespBody) > 0 {
+		resp.Content.Text = base64.StdEncoding.EncodeToString(entry.RespBody)
+		resp.Content.Encoding = "base64"
+	}
+	if entry.Err != nil {
+		resp.StatusText = entry.Err.Error()
+	}
+
+	return HAREntry{
+		StartedDateTime: entry.Time.Format(time.RFC3339Nano),
+		Time:            float64(entry.Duration) / float64(time.Millisecond),
+		Request:         req,
+		Response:        resp,
+		Timings: HARTimings{
+			Send:    0,
+			Wait:    float64(entry.Duration) / float64(time.Millisecond),
+			Receive: 0,
+		},
+	}
+}
+
+func isTextContent(mimeType string, body []byte) bool {
+	if mimeType == "" {
+		return utf8.Valid(body)
+	}
+	return strings.HasPrefix(mimeType, "text/") ||
+		strings.Contains(mimeType, "json") || strings.Contains(mimeType, "xml") ||
+		strings.Contains(mimeType, "javascript") || strings.Contains(mimeType, "x-www-form-urlencoded")
+}
+
+func harNameValues(values map[string][]string) []HARNameValue {
+	pairs := make([]HARNameValue, 0, len(values))
+	for _, name := range sortedKeys(values) {
+		for _, value := range values[name] {
+			pairs = append(pairs, HARNameValue{Name: name, Value: value})
+		}
+	}
+	return pairs
+}
+
+func harHeader(pairs []HARNameValue) http.Header {
+	header := http.Header{}
+	for _, pair := range pairs {
+		if strings.HasPrefix(pair.Name, ":") {
+			continue
+		}
+		header.Add(pair.Name, pair.Value)
+	}
+	return header
+}
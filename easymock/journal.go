@@ -0,0 +1,163 @@
+package easymock
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type JournalEntry struct {
+	Method     string
+	URL        string
+	Header     http.Header
+	Body       []byte
+	Route      string
+	Matched    bool
+	StatusCode int
+	RespHeader http.Header
+	RespBody   []byte
+	Err        error
+	Time       time.Time
+	Duration   time.Duration
+
+	key routeKey
+}
+
+type journalBody struct {
+	io.ReadCloser
+	mu    *sync.Mutex
+	entry *JournalEntry
+}
+
+func (jb *journalBody) Read(p []byte) (int, error) {
+	n, err := jb.ReadCloser.Read(p)
+	if n > 0 {
+		jb.mu.Lock()
+		jb.entry.RespBody = append(jb.entry.RespBody, p[:n]...)
+		jb.mu.Unlock()
+	}
+	return n, err
+}
+
+// newJournalEntry captures req and returns the request to dispatch. The
+// body is read once and replayed from a clone, req itself is left as is.
+func newJournalEntry(req *http.Request, method string) (*JournalEntry, *http.Request) {
+	entry := &JournalEntry{
+		Method: method,
+		URL:    req.URL.String(),
+		Header: req.Header.Clone(),
+		Time:   time.Now(),
+	}
+	if req.Body == nil || req.Body == http.NoBody {
+		return entry, req
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	entry.Body = body
+	if err != nil {
+		entry.Err = err
+	}
+	cloned := req.Clone(req.Context())
+	cloned.Body = ioutil.NopCloser(bytes.NewReader(body))
+	cloned.GetBody = func() (io.ReadCloser, error) {
+		return ioutil.NopCloser(bytes.NewReader(body)), nil
+	}
+	return entry, cloned
+}
+
+func (entry *JournalEntry) answeredBy(key routeKey) {
+	entry.key = key
+	entry.Matched = true
+	entry.Route = key.String()
+}
+
+// record appends entry to the journal. Bodies of in-memory responses are
+// copied right away, other bodies are captured while the client reads them.
+func (mocker *EasyMocker) record(entry *JournalEntry, resp *http.Response, err error) {
+	entry.Duration = time.Since(entry.Time)
+	if resp != nil {
+		entry.StatusCode = resp.StatusCode
+		entry.RespHeader = resp.Header.Clone()
+		switch body := resp.Body.(type) {
+		case nil:
+		case *easyResponse:
+			entry.RespBody = body.bytes()
+		default:
+			resp.Body = &journalBody{ReadCloser: body, mu: &mocker.journalMu, entry: entry}
+		}
+	}
+	if err != nil {
+		entry.Err = err
+	}
+	mocker.journalMu.Lock()
+	mocker.journal = append(mocker.journal, entry)
+	mocker.journalMu.Unlock()
+}
+
+func (mocker *EasyMocker) Journal() []JournalEntry {
+	return mocker.filterJournal(func(entry *JournalEntry) bool {
+		return true
+	})
+}
+
+func (mocker *EasyMocker) Requests(method, url string) []JournalEntry {
+	return mocker.requestsOf(mocker.exactRouteKey(method, url))
+}
+
+func (mocker *EasyMocker) RegexRequests(method, pattern string) []JournalEntry {
+	return mocker.requestsOf(routeKey{kind: regexRouteKind, rt: router{Method: method, Url: pattern}})
+}
+
+func (mocker *EasyMocker) TemplateRequests(method, template string) []JournalEntry {
+	return mocker.requestsOf(routeKey{kind: templateRouteKind, rt: router{Method: method, Url: template}})
+}
+
+func (mocker *EasyMocker) requestsOf(key routeKey) []JournalEntry {
+	return mocker.filterJournal(func(entry *JournalEntry) bool {
+		return entry.Matched && entry.key == key
+	})
+}
+
+func (mocker *EasyMocker) UnmatchedRequests() []JournalEntry {
+	return mocker.filterJournal(func(entry *JournalEntry) bool {
+		return !entry.Matched
+	})
+}
+
+func (mocker *EasyMocker) LastRequest() (JournalEntry, bool) {
+	mocker.journalMu.Lock()
+	defer mocker.journalMu.Unlock()
+	if len(mocker.journal) == 0 {
+		return JournalEntry{}, false
+	}
+	return mocker.journal[len(mocker.journal)-1].copy(), true
+}
+
+func (mocker *EasyMocker) ResetJournal() {
+	mocker.journalMu.Lock()
+	mocker.journal = nil
+	mocker.journalMu.Unlock()
+}
+
+func (mocker *EasyMocker) filterJournal(keep func(entry *JournalEntry) bool) []JournalEntry {
+	mocker.journalMu.Lock()
+	defer mocker.journalMu.Unlock()
+	entries := make([]JournalEntry, 0)
+	for _, entry := range mocker.journal {
+		if keep(entry) {
+			entries = append(entries, entry.copy())
+		}
+	}
+	return entries
+}
+
+func (entry *JournalEntry) copy() JournalEntry {
+	copied := *entry
+	if entry.RespBody != nil {
+		copied.RespBody = append([]byte(nil), entry.RespBody...)
+	}
+	return copied
+}
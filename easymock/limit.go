@@ -0,0 +1,64 @@
+package easymock
+
+import "time"
+
+func (eR *EasyResponder) Once() *EasyResponder {
+	return eR.Times(1)
+}
+
+func (eR *EasyResponder) Times(n int) *EasyResponder {
+	if n <= 0 {
+		panic("responder must be allowed to answer at least once")
+	}
+	eR.mu.Lock()
+	eR.maxUses = n
+	eR.mu.Unlock()
+	return eR
+}
+
+func (eR *EasyResponder) ExpireAt(deadline time.Time) *EasyResponder {
+	eR.mu.Lock()
+	eR.deadline = deadline
+	eR.mu.Unlock()
+	return eR
+}
+
+func (eR *EasyResponder) ExpireAfter(d time.Duration) *EasyResponder {
+	return eR.ExpireAt(time.Now().Add(d))
+}
+
+func (eR *EasyResponder) Uses() int {
+	eR.mu.Lock()
+	defer eR.mu.Unlock()
+	return eR.uses
+}
+
+func (eR *EasyResponder) ResetUses() {
+	eR.mu.Lock()
+	eR.uses = 0
+	eR.mu.Unlock()
+}
+
+func (eR *EasyResponder) acquire() bool {
+	eR.mu.Lock()
+	defer eR.mu.Unlock()
+	if !eR.isAvailableLocked() {
+		return false
+	}
+	eR.uses++
+	return true
+}
+
+func (eR *EasyResponder) isAvailableLocked() bool {
+	if !eR.available {
+		return false
+	}
+	if eR.maxUses > 0 && eR.uses >= eR.maxUses {
+		return false
+	}
+	return eR.deadline.IsZero() || time.Now().Before(eR.deadline)
+}
+
+func (eR *EasyResponder) isLimitedLocked() bool {
+	return eR.maxUses > 0 || !eR.deadline.IsZero()
+}
@@ -0,0 +1,288 @@
+package easymock
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type Matcher interface {
+	Match(req *http.Request) bool
+	String() string
+}
+
+type matcherFunc struct {
+	desc  string
+	match func(req *http.Request) bool
+}
+
+func (mf *matcherFunc) Match(req *http.Request) bool {
+	return mf.match(req)
+}
+
+func (mf *matcherFunc) String() string {
+	return mf.desc
+}
+
+func NewMatcher(desc string, match func(req *http.Request) bool) Matcher {
+	return &matcherFunc{desc: desc, match: match}
+}
+
+func And(matchers ...Matcher) Matcher {
+	return NewMatcher(joinMatchers("and", matchers), func(req *http.Request) bool {
+		for _, m := range matchers {
+			if !m.Match(req) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+func Or(matchers ...Matcher) Matcher {
+	return NewMatcher(joinMatchers("or", matchers), func(req *http.Request) bool {
+		for _, m := range matchers {
+			if m.Match(req) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func Not(matcher Matcher) Matcher {
+	return NewMatcher("not("+matcher.String()+")", func(req *http.Request) bool {
+		return !matcher.Match(req)
+	})
+}
+
+func joinMatchers(op string, matchers []Matcher) string {
+	descs := make([]string, 0, len(matchers))
+	for _, m := range matchers {
+		descs = append(descs, m.String())
+	}
+	return op + "(" + strings.Join(descs, ", ") + ")"
+}
+
+func HostEquals(host string) Matcher {
+	return NewMatcher(fmt.Sprintf("host == %q", host), func(req *http.Request) bool {
+		return strings.EqualFold(req.URL.Hostname(), host) || strings.EqualFold(req.URL.Host, host)
+	})
+}
+
+func HeaderPresent(name string) Matcher {
+	return NewMatcher(fmt.Sprintf("header %s present", name), func(req *http.Request) bool {
+		_, ok := req.Header[http.CanonicalHeaderKey(name)]
+		return ok
+	})
+}
+
+func HeaderEquals(name, value string) Matcher {
+	return NewMatcher(fmt.Sprintf("header %s == %q", name, value), func(req *http.Request) bool {
+		for _, v := range req.Header.Values(name) {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func HeaderContains(name, substr string) Matcher {
+	return NewMatcher(fmt.Sprintf("header %s contains %q", name, substr), func(req *http.Request) bool {
+		for _, v := range req.Header.Values(name) {
+			if strings.Contains(v, substr) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func HeaderMatches(name, pattern string) Matcher {
+	re := regexp.MustCompile(pattern)
+	return NewMatcher(fmt.Sprintf("header %s matches %q", name, pattern), func(req *http.Request) bool {
+		for _, v := range req.Header.Values(name) {
+			if re.MatchString(v) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func QueryPresent(name string) Matcher {
+	return NewMatcher(fmt.Sprintf("query %s present", name), func(req *http.Request) bool {
+		_, ok := req.URL.Query()[name]
+		return ok
+	})
+}
+
+func QueryEquals(name, value string) Matcher {
+	return NewMatcher(fmt.Sprintf("query %s == %q", name, value), func(req *http.Request) bool {
+		for _, v := range req.URL.Query()[name] {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func CookiePresent(name string) Matcher {
+	return NewMatcher(fmt.Sprintf("cookie %s present", name), func(req *http.Request) bool {
+		_, err := req.Cookie(name)
+		return err == nil
+	})
+}
+
+func CookieEquals(name, value string) Matcher {
+	return NewMatcher(fmt.Sprintf("cookie %s == %q", name, value), func(req *http.Request) bool {
+		cookie, err := req.Cookie(name)
+		return err == nil && cookie.Value == value
+	})
+}
+
+func BasicAuthEquals(username, password string) Matcher {
+	return NewMatcher(fmt.Sprintf("basic auth user == %q", username), func(req *http.Request) bool {
+		u, p, ok := req.BasicAuth()
+		return ok && u == username && p == password
+	})
+}
+
+func BearerTokenEquals(token string) Matcher {
+	return NewMatcher("bearer token matches", func(req *http.Request) bool {
+		auth := req.Header.Get("Authorization")
+		const prefix = "bearer "
+		return len(auth) > len(prefix) && strings.EqualFold(auth[:len(prefix)], prefix) &&
+			auth[len(prefix):] == token
+	})
+}
+
+func FormFieldEquals(name, value string) Matcher {
+	return NewMatcher(fmt.Sprintf("form %s == %q", name, value), func(req *http.Request) bool {
+		body, err := peekBody(req)
+		if err != nil {
+			return false
+		}
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return false
+		}
+		for _, v := range form[name] {
+			if v == value {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+func BodyMatches(pattern string) Matcher {
+	re := regexp.MustCompile(pattern)
+	return NewMatcher(fmt.Sprintf("body matches %q", pattern), func(req *http.Request) bool {
+		body, err := peekBody(req)
+		return err == nil && re.Match(body)
+	})
+}
+
+// JSONBodyFieldEquals compares the value found at path, such as $.user.tags[0]
+// or user.tags.0, with value after both are converted to their JSON form.
+func JSONBodyFieldEquals(path string, value interface{}) Matcher {
+	expected, expectedErr := toJSONValue(value)
+	return NewMatcher(fmt.Sprintf("json %s == %v", path, value), func(req *http.Request) bool {
+		if expectedErr != nil {
+			return false
+		}
+		actual, ok := lookupJSONBody(req, path)
+		return ok && reflect.DeepEqual(expected, actual)
+	})
+}
+
+func JSONBodyFieldPresent(path string) Matcher {
+	return NewMatcher(fmt.Sprintf("json %s present", path), func(req *http.Request) bool {
+		_, ok := lookupJSONBody(req, path)
+		return ok
+	})
+}
+
+func lookupJSONBody(req *http.Request, path string) (interface{}, bool) {
+	body, err := peekBody(req)
+	if err != nil {
+		return nil, false
+	}
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, false
+	}
+	return lookupJSONPath(doc, path)
+}
+
+func toJSONValue(value interface{}) (interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var v interface{}
+	err = json.Unmarshal(b, &v)
+	return v, err
+}
+
+func lookupJSONPath(doc interface{}, path string) (interface{}, bool) {
+	for _, segment := range splitJSONPath(path) {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			child, ok := node[segment]
+			if !ok {
+				return nil, false
+			}
+			doc = child
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+			doc = node[i]
+		default:
+			return nil, false
+		}
+	}
+	return doc, true
+}
+
+func splitJSONPath(path string) []string {
+	path = strings.TrimPrefix(strings.TrimPrefix(path, "$"), ".")
+	path = strings.NewReplacer("[", ".", "]", "").Replace(path)
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(path, ".") {
+		segment = strings.Trim(segment, `'"`)
+		if segment != "" {
+			segments = append(segments, segment)
+		}
+	}
+	return segments
+}
+
+func peekBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err == nil {
+			defer body.Close()
+			return ioutil.ReadAll(body)
+		}
+	}
+	b, err := ioutil.ReadAll(req.Body)
+	_ = req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(b))
+	return b, err
+}
@@ -3,29 +3,45 @@ package easymock
 import (
 	"fmt"
 	"net/http"
-	"regexp"
 	"sync"
+	"testing"
 )
 
 var (
 	OriginTransport = http.DefaultTransport
 	MockerTransport = NewEasyMockerTransport()
-	OldClients      map[*http.Client]http.RoundTripper
+	OldClients      = MockerTransport.oldClients
 
 	globalMu sync.Mutex
 
 	routingFailedTmpl   = `routing failed, no responders were found for url '%s'`
 	urlNotAvailableTmpl = `url '%s' is not available`
+	routeExistsTmpl     = `responder of [%s - %s] already exists`
+	routeIDExistsTmpl   = `route with id '%s' already exists`
 )
 
+var _ http.RoundTripper = (*EasyMocker)(nil)
+
 type EasyMocker struct {
 	responderMu           sync.Mutex
 	matchCntMu, missCntMu sync.Mutex
-	responderMap          map[router]*EasyResponder
-	regexResponderMap     map[router]*EasyRegexResponder
-	matchedCounter        map[router]int
+	routes                []*route
+	routeSeq              int
+	matchedCounter        map[routeKey]int
 	mismatchCounter       map[router]int
+	expectations          []*routeExpectation
+	journalMu             sync.Mutex
+	journal               []*JournalEntry
 	totalCount            int
+	originTransport       http.RoundTripper
+	oldClients            map[*http.Client]http.RoundTripper
+	fallback              fallbackPolicy
+	chaos                 *chaos
+	recorder              *recorder
+	scenarioMu            sync.Mutex
+	scenarios             map[string]string
+	admin                 bool
+	t                     testing.TB
 }
 
 type router struct {
@@ -35,14 +51,15 @@ type router struct {
 
 func NewEasyMockerTransport() *EasyMocker {
 	return &EasyMocker{
-		responderMu:       sync.Mutex{},
-		matchCntMu:        sync.Mutex{},
-		missCntMu:         sync.Mutex{},
-		responderMap:      make(map[router]*EasyResponder),
-		regexResponderMap: make(map[router]*EasyRegexResponder),
-		matchedCounter:    make(map[router]int),
-		mismatchCounter:   make(map[router]int),
-		totalCount:        0,
+		responderMu:     sync.Mutex{},
+		matchCntMu:      sync.Mutex{},
+		missCntMu:       sync.Mutex{},
+		matchedCounter:  make(map[routeKey]int),
+		mismatchCounter: make(map[router]int),
+		totalCount:      0,
+		originTransport: OriginTransport,
+		oldClients:      make(map[*http.Client]http.RoundTripper),
+		scenarios:       make(map[string]string),
 	}
 }
 
@@ -51,34 +68,89 @@ func Start() {
 	if http.DefaultTransport != MockerTransport {
 		OriginTransport = http.DefaultTransport
 	}
-	http.DefaultTransport = MockerTransport
 	globalMu.Unlock()
+	MockerTransport.Start()
 }
 
 func StartWithClient(client *http.Client) {
+	MockerTransport.StartWithClient(client)
+}
+
+func Reset() {
+	MockerTransport.Reset()
+}
+
+func Shutdown() {
+	MockerTransport.Shutdown()
+}
+
+func RegisterResponder(method, url string, responder *EasyResponder) {
+	MockerTransport.RegisterResponder(method, url, responder)
+}
+
+func RegisterRegexResponder(method, url string, regexResponder *EasyRegexResponder) {
+	MockerTransport.RegisterRegexResponder(method, url, regexResponder)
+}
+
+func RegisterResponderWithQuery(method, url string, mode QueryMatch, responder *EasyResponder) {
+	MockerTransport.RegisterResponderWithQuery(method, url, mode, responder)
+}
+
+func RemoveResponder(method, url string) {
+	MockerTransport.RemoveResponder(method, url)
+}
+
+func RemoveRegexResponder(method, url string) {
+	MockerTransport.RemoveRegexResponder(method, url)
+}
+
+func (mocker *EasyMocker) Start() {
 	globalMu.Lock()
-	if _, exist := OldClients[client]; exist {
-		OldClients[client] = client.Transport
+	if http.DefaultTransport != mocker {
+		mocker.originTransport = http.DefaultTransport
 	}
-	client.Transport = MockerTransport
+	http.DefaultTransport = mocker
 	globalMu.Unlock()
 }
 
-func Reset() {
+// StartWithClient routes the requests of client through the mocker. Requests
+// that are passed through still use the previous transport of client.
+func (mocker *EasyMocker) StartWithClient(client *http.Client) {
 	globalMu.Lock()
-	MockerTransport.matchedCounter = make(map[router]int)
-	MockerTransport.responderMap = make(map[router]*EasyResponder)
-	MockerTransport.mismatchCounter = make(map[router]int)
-	MockerTransport.totalCount = 0
+	if _, exist := mocker.oldClients[client]; !exist {
+		mocker.oldClients[client] = client.Transport
+	}
+	client.Transport = &clientTransport{mocker: mocker, origin: mocker.oldClients[client]}
 	globalMu.Unlock()
 }
 
-func Shutdown() {
+func (mocker *EasyMocker) Reset() {
+	mocker.responderMu.Lock()
+	mocker.routes = nil
+	mocker.responderMu.Unlock()
+
+	mocker.matchCntMu.Lock()
+	mocker.matchedCounter = make(map[routeKey]int)
+	mocker.expectations = nil
+	mocker.matchCntMu.Unlock()
+
+	mocker.missCntMu.Lock()
+	mocker.mismatchCounter = make(map[router]int)
+	mocker.totalCount = 0
+	mocker.missCntMu.Unlock()
+
+	mocker.ResetJournal()
+	mocker.ResetScenarios()
+}
+
+func (mocker *EasyMocker) Shutdown() {
 	globalMu.Lock()
-	http.DefaultTransport = OriginTransport
-	for client, transport := range OldClients {
+	if http.DefaultTransport == mocker {
+		http.DefaultTransport = mocker.originTransport
+	}
+	for client, transport := range mocker.oldClients {
 		client.Transport = transport
-		delete(OldClients, client)
+		delete(mocker.oldClients, client)
 	}
 	globalMu.Unlock()
 }
@@ -94,81 +166,115 @@ func (mocker *EasyMocker) RoundTrip(req *http.Request) (*http.Response, error) {
 		Method: method,
 		Url:    url,
 	}
+	mocker.updateTotalCount()
+	entry, req := newJournalEntry(req, method)
+
+	resp, err := mocker.dispatch(req, rt, entry)
+	mocker.record(entry, resp, err)
+	return resp, err
+}
 
-	responder, ok := mocker.responderMap[rt]
-	if ok && responder.IsAvailable() {
-		mocker.updateMatchCount(rt)
-		return (*responder).reqHandler(req)
+func (mocker *EasyMocker) dispatch(req *http.Request, rt router, entry *JournalEntry) (*http.Response, error) {
+	if rec := mocker.activeRecorder(); rec != nil {
+		return mocker.recordRoundTrip(rec, req, entry)
 	}
 
-	regexpResponder, regexOk := mocker.findRegexResponder(rt)
-	if regexOk && regexpResponder.IsAvailable() {
-		mocker.updateMatchCount(rt)
-		return (*regexpResponder).reqHandler(req)
+	matched, params, found := mocker.findRoute(req, rt.Method)
+	for found && matched.responder.IsAvailable() {
+		if !matched.responder.acquire() {
+			matched, params, found = mocker.findRoute(req, rt.Method)
+			continue
+		}
+		mocker.updateMatchCount(matched.key)
+		mocker.transition(matched.responder)
+		entry.answeredBy(matched.key)
+		if matched.key.kind == templateRouteKind {
+			req = withPathParams(req, params)
+		}
+		mocker.responderMu.Lock()
+		mockerChaos := mocker.chaos
+		mocker.responderMu.Unlock()
+		if mockerChaos != nil {
+			return mockerChaos.apply(req, matched.responder.respond)
+		}
+		return matched.responder.respond(req)
 	}
 
 	mocker.updateMismatchCount(rt)
-	return mocker.connectFail(req,
-		(ok && !responder.IsAvailable()) ||
-		(regexOk && !regexpResponder.IsAvailable()))
+	return mocker.handleUnmatched(req, rt, entry, found)
 }
 
-func (mocker *EasyMocker) findRegexResponder(rt router) (*EasyRegexResponder, bool) {
-	for _, regexResponder := range mocker.regexResponderMap {
-		if regexResponder.isMatched(rt.Url) {
-			return regexResponder, true
-		}
-	}
-	return nil, false
+func (mocker *EasyMocker) RegisterResponder(method, url string, responder *EasyResponder) {
+	mocker.RegisterResponderWithQuery(method, url, QueryExact, responder)
 }
 
-func RegisterResponder(method, url string, responder *EasyResponder) {
-	rt := router{
-		Method: method,
-		Url:    url,
-	}
+func (mocker *EasyMocker) RegisterResponderWithQuery(method, url string, mode QueryMatch, responder *EasyResponder) {
+	registerFailed(mocker.registerExact(method, url, mode, responder))
+}
 
-	MockerTransport.responderMu.Lock()
-	defer MockerTransport.responderMu.Unlock()
+func (mocker *EasyMocker) RegisterRegexResponder(method, url string, regexResponder *EasyRegexResponder) {
+	registerFailed(mocker.registerRegex(method, url, regexResponder))
+}
 
-	if _, ok := MockerTransport.responderMap[rt]; !ok {
-		MockerTransport.responderMap[rt] = responder
-		MockerTransport.matchedCounter[rt] = 0
-	} else {
-		registerFailed(rt)
+func (mocker *EasyMocker) registerExact(method, url string, mode QueryMatch, responder *EasyResponder) error {
+	r, err := newExactRoute(method, url, mode, responder)
+	if err != nil {
+		return err
 	}
+	return mocker.addRoute(r)
 }
 
-func RegisterRegexResponder(method, url string, regexResponder *EasyRegexResponder) {
-	rt := router{
-		Method: method,
-		Url:    url,
+func (mocker *EasyMocker) registerRegex(method, url string, regexResponder *EasyRegexResponder) error {
+	r, err := newRegexRoute(method, url, regexResponder)
+	if err != nil {
+		return err
 	}
-	MockerTransport.responderMu.Lock()
-	defer MockerTransport.responderMu.Unlock()
+	return mocker.addRoute(r)
+}
 
-	if _, ok := MockerTransport.regexResponderMap[rt]; !ok {
-		regexResponder.oriUrl = url
-		regexResponder.matcher = regexp.MustCompile(url)
-		MockerTransport.regexResponderMap[rt] = regexResponder
-		MockerTransport.matchedCounter[rt] = 0
-	} else {
-		registerFailed(rt)
+func registerFailed(err error) {
+	if err != nil {
+		panic(err.Error())
 	}
 }
 
-func registerFailed(rt router) {
-	panic(fmt.Sprintf("responder of [%s - %s] already exists", rt.Method, rt.Url))
+func (mocker *EasyMocker) RemoveResponder(method, url string) {
+	mocker.removeRoutes(mocker.exactRouteKey(method, url))
 }
 
-func RemoveResponder(method, url string) {
-	rt := router{
-		Method: method,
-		Url:    url,
+// exactRouteKey returns the key of the exact route registered for method and
+// url. Routes registered with QueryIgnore are keyed without their query, so
+// they are looked up by their stored mode when no QueryExact route matches.
+func (mocker *EasyMocker) exactRouteKey(method, url string) routeKey {
+	base, query, err := parseAndNormalizeUrl(url)
+	if err != nil {
+		return routeKey{kind: exactRouteKind, rt: router{Method: method, Url: url}}
+	}
+	key := routeKey{kind: exactRouteKind, rt: router{Method: method, Url: normalizedRouteUrl(base, query, QueryExact)}}
+	if len(query) == 0 {
+		return key
+	}
+	ignored := routeKey{kind: exactRouteKind, rt: router{Method: method, Url: normalizedRouteUrl(base, query, QueryIgnore)}}
+
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+	found := false
+	for _, r := range mocker.routes {
+		if r.key == key {
+			return key
+		}
+		if r.key == ignored && r.mode == QueryIgnore && queryMatched(r.query, query, QueryExact) {
+			found = true
+		}
+	}
+	if found {
+		return ignored
 	}
-	MockerTransport.responderMu.Lock()
-	delete(MockerTransport.responderMap, rt)
-	MockerTransport.responderMu.Unlock()
+	return key
+}
+
+func (mocker *EasyMocker) RemoveRegexResponder(method, url string) {
+	mocker.removeRoutes(routeKey{kind: regexRouteKind, rt: router{Method: method, Url: url}})
 }
 
 func (mocker *EasyMocker) connectFail(req *http.Request, available bool) (*http.Response, error) {
@@ -178,12 +284,18 @@ func (mocker *EasyMocker) connectFail(req *http.Request, available bool) (*http.
 	return nil, fmt.Errorf(urlNotAvailableTmpl, req.URL.Scheme+`://`+req.URL.Host)
 }
 
-func (mocker *EasyMocker) updateMatchCount(rt router) {
+func (mocker *EasyMocker) updateTotalCount() {
+	mocker.missCntMu.Lock()
+	mocker.totalCount++
+	mocker.missCntMu.Unlock()
+}
+
+func (mocker *EasyMocker) updateMatchCount(key routeKey) {
 	mocker.matchCntMu.Lock()
-	if _, exist := mocker.matchedCounter[rt]; exist {
-		mocker.matchedCounter[rt]++
+	if _, exist := mocker.matchedCounter[key]; exist {
+		mocker.matchedCounter[key]++
 	} else {
-		mocker.matchedCounter[rt] = 1
+		mocker.matchedCounter[key] = 1
 	}
 	mocker.matchCntMu.Unlock()
 }
@@ -0,0 +1,99 @@
+package easymock
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+type QueryMatch int
+
+const (
+	QueryExact QueryMatch = iota
+	QuerySubset
+	QueryIgnore
+)
+
+func (qm QueryMatch) String() string {
+	switch qm {
+	case QuerySubset:
+		return "query subset"
+	case QueryIgnore:
+		return "ignore query"
+	}
+	return "exact query"
+}
+
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+func normalizeUrl(u *url.URL) (string, url.Values) {
+	scheme := strings.ToLower(u.Scheme)
+	host := strings.ToLower(u.Host)
+	if port := u.Port(); port != "" && defaultPorts[scheme] == port {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	path := (&url.URL{Path: u.Path}).EscapedPath()
+	path = strings.TrimRight(path, "/")
+
+	base := path
+	if host != "" {
+		base = scheme + "://" + host + path
+	}
+
+	query, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		query = url.Values{}
+	}
+	for _, values := range query {
+		sort.Strings(values)
+	}
+	return base, query
+}
+
+func parseAndNormalizeUrl(rawUrl string) (string, url.Values, error) {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return "", nil, err
+	}
+	base, query := normalizeUrl(u)
+	return base, query, nil
+}
+
+func normalizedRouteUrl(base string, query url.Values, mode QueryMatch) string {
+	if mode == QueryIgnore || len(query) == 0 {
+		return base
+	}
+	return base + "?" + query.Encode()
+}
+
+func queryMatched(expected, actual url.Values, mode QueryMatch) bool {
+	switch mode {
+	case QueryIgnore:
+		return true
+	case QuerySubset:
+		for key, values := range expected {
+			if !isSubMultiset(values, actual[key]) {
+				return false
+			}
+		}
+		return true
+	}
+	return expected.Encode() == actual.Encode()
+}
+
+func isSubMultiset(sub, set []string) bool {
+	counts := make(map[string]int, len(set))
+	for _, s := range set {
+		counts[s]++
+	}
+	for _, s := range sub {
+		if counts[s] == 0 {
+			return false
+		}
+		counts[s]--
+	}
+	return true
+}
@@ -0,0 +1,720 @@
+package easymock
+
+import (
+	"encoding/json"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io/ioutil"
+	"math"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const openAPIMaxDepth = 8
+
+type OpenAPI struct {
+	OpenAPI    string                      `json:"openapi" yaml:"openapi"`
+	Servers    []OpenAPIServer             `json:"servers,omitempty" yaml:"servers,omitempty"`
+	Paths      map[string]*OpenAPIPathItem `json:"paths" yaml:"paths"`
+	Components OpenAPIComponents           `json:"components,omitempty" yaml:"components,omitempty"`
+}
+
+type OpenAPIServer struct {
+	URL       string                           `json:"url" yaml:"url"`
+	Variables map[string]OpenAPIServerVariable `json:"variables,omitempty" yaml:"variables,omitempty"`
+}
+
+type OpenAPIServerVariable struct {
+	Default string `json:"default" yaml:"default"`
+}
+
+type OpenAPIPathItem struct {
+	Parameters []*OpenAPIParameter `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	Get        *OpenAPIOperation   `json:"get,omitempty" yaml:"get,omitempty"`
+	Put        *OpenAPIOperation   `json:"put,omitempty" yaml:"put,omitempty"`
+	Post       *OpenAPIOperation   `json:"post,omitempty" yaml:"post,omitempty"`
+	Delete     *OpenAPIOperation   `json:"delete,omitempty" yaml:"delete,omitempty"`
+	Options    *OpenAPIOperation   `json:"options,omitempty" yaml:"options,omitempty"`
+	Head       *OpenAPIOperation   `json:"head,omitempty" yaml:"head,omitempty"`
+	Patch      *OpenAPIOperation   `json:"patch,omitempty" yaml:"patch,omitempty"`
+}
+
+type OpenAPIOperation struct {
+	OperationID string                      `json:"operationId,omitempty" yaml:"operationId,omitempty"`
+	Parameters  []*OpenAPIParameter         `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *OpenAPIRequestBody         `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*OpenAPIResponse `json:"responses" yaml:"responses"`
+}
+
+type OpenAPIParameter struct {
+	Ref      string         `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Name     string         `json:"name,omitempty" yaml:"name,omitempty"`
+	In       string         `json:"in,omitempty" yaml:"in,omitempty"`
+	Required bool           `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *OpenAPISchema `json:"schema,omitempty" yaml:"schema,omitempty"`
+}
+
+type OpenAPIRequestBody struct {
+	Ref      string                       `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Required bool                         `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]*OpenAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type OpenAPIResponse struct {
+	Ref     string                       `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Content map[string]*OpenAPIMediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+type OpenAPIMediaType struct {
+	Schema   *OpenAPISchema             `json:"schema,omitempty" yaml:"schema,omitempty"`
+	Example  interface{}                `json:"example,omitempty" yaml:"example,omitempty"`
+	Examples map[string]*OpenAPIExample `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
+
+type OpenAPIExample struct {
+	Ref   string      `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Value interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+type OpenAPIComponents struct {
+	Schemas       map[string]*OpenAPISchema      `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	Parameters    map[string]*OpenAPIParameter   `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBodies map[string]*OpenAPIRequestBody `json:"requestBodies,omitempty" yaml:"requestBodies,omitempty"`
+	Responses     map[string]*OpenAPIResponse    `json:"responses,omitempty" yaml:"responses,omitempty"`
+	Examples      map[string]*OpenAPIExample     `json:"examples,omitempty" yaml:"examples,omitempty"`
+}
+
+type OpenAPISchema struct {
+	Ref                  string                    `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string                    `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string                    `json:"format,omitempty" yaml:"format,omitempty"`
+	Nullable             bool                      `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Enum                 []interface{}             `json:"enum,omitempty" yaml:"enum,omitempty"`
+	Example              interface{}               `json:"example,omitempty" yaml:"example,omitempty"`
+	Default              interface{}               `json:"default,omitempty" yaml:"default,omitempty"`
+	Properties           map[string]*OpenAPISchema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string                  `json:"required,omitempty" yaml:"required,omitempty"`
+	AdditionalProperties interface{}               `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Items                *OpenAPISchema            `json:"items,omitempty" yaml:"items,omitempty"`
+	MinItems             *int                      `json:"minItems,omitempty" yaml:"minItems,omitempty"`
+	MaxItems             *int                      `json:"maxItems,omitempty" yaml:"maxItems,omitempty"`
+	MinLength            *int                      `json:"minLength,omitempty" yaml:"minLength,omitempty"`
+	MaxLength            *int                      `json:"maxLength,omitempty" yaml:"maxLength,omitempty"`
+	Pattern              string                    `json:"pattern,omitempty" yaml:"pattern,omitempty"`
+	Minimum              *float64                  `json:"minimum,omitempty" yaml:"minimum,omitempty"`
+	Maximum              *float64                  `json:"maximum,omitempty" yaml:"maximum,omitempty"`
+	AllOf                []*OpenAPISchema          `json:"allOf,omitempty" yaml:"allOf,omitempty"`
+	OneOf                []*OpenAPISchema          `json:"oneOf,omitempty" yaml:"oneOf,omitempty"`
+	AnyOf                []*OpenAPISchema          `json:"anyOf,omitempty" yaml:"anyOf,omitempty"`
+}
+
+type OpenAPIOptions struct {
+	// BaseURL replaces the first server url of the document, it is
+	// required when the document has no absolute server url.
+	BaseURL          string
+	ValidateRequests bool
+}
+
+func LoadOpenAPI(path string) (*OpenAPI, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseOpenAPI(path, data)
+}
+
+func ParseOpenAPI(name string, data []byte) (*OpenAPI, error) {
+	doc := &OpenAPI{}
+	var err error
+	if strings.ToLower(filepath.Ext(name)) == ".json" {
+		err = json.Unmarshal(data, doc)
+	} else {
+		err = yaml.Unmarshal(data, doc)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", name, err)
+	}
+	if !strings.HasPrefix(doc.OpenAPI, "3.") {
+		return nil, fmt.Errorf("%s: unsupported openapi version '%s'", name, doc.OpenAPI)
+	}
+	return doc, nil
+}
+
+func (doc *OpenAPI) baseURL(opts OpenAPIOptions) (string, error) {
+	base := opts.BaseURL
+	if base == "" && len(doc.Servers) > 0 {
+		server := doc.Servers[0]
+		base = server.URL
+		for name, variable := range server.Variables {
+			base = strings.Replace(base, "{"+name+"}", variable.Default, -1)
+		}
+	}
+	if !strings.Contains(base, "://") {
+		return "", fmt.Errorf("server url '%s' is not absolute, set OpenAPIOptions.BaseURL", base)
+	}
+	return strings.TrimSuffix(base, "/"), nil
+}
+
+func (item *OpenAPIPathItem) operations() map[string]*OpenAPIOperation {
+	ops := map[string]*OpenAPIOperation{
+		http.MethodGet:     item.Get,
+		http.MethodPut:     item.Put,
+		http.MethodPost:    item.Post,
+		http.MethodDelete:  item.Delete,
+		http.MethodOptions: item.Options,
+		http.MethodHead:    item.Head,
+		http.MethodPatch:   item.Patch,
+	}
+	for method, op := range ops {
+		if op == nil {
+			delete(ops, method)
+		}
+	}
+	return ops
+}
+
+// RegisterOpenAPI registers a template responder for every operation of
+// doc. Responses are taken from examples or synthesized from the schema.
+// Nothing is registered if one of the operations cannot be.
+func (mocker *EasyMocker) RegisterOpenAPI(doc *OpenAPI, opts OpenAPIOptions) error {
+	base, err := doc.baseURL(opts)
+	if err != nil {
+		return err
+	}
+
+	paths := make([]string, 0, len(doc.Paths))
+	for path := range doc.Paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	routes := make([]*route, 0)
+	names := make([]string, 0)
+	for _, path := range paths {
+		item := doc.Paths[path]
+		if item == nil {
+			return fmt.Errorf("%s: path item is null", path)
+		}
+		ops := item.operations()
+		methods := make([]string, 0, len(ops))
+		for method := range ops {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		for _, method := range methods {
+			op := &openAPIOperation{doc: doc, op: ops[method], params: doc.parameters(item, ops[method])}
+			resp, err := op.exampleResponse()
+			if err != nil {
+				return fmt.Errorf("%s %s: %v", method, path, err)
+			}
+			handler := cloneRespHandler(resp)
+			if opts.ValidateRequests {
+				handler = op.validating(handler)
+			}
+			r, err := newTemplateRoute(method, base+path, NewEasyResponderWithReqHandler(handler))
+			if err != nil {
+				return fmt.Errorf("%s %s: %v", method, path, err)
+			}
+			routes = append(routes, r)
+			names = append(names, method+" "+path)
+		}
+	}
+	if i, err := mocker.addRoutes(routes); err != nil {
+		return fmt.Errorf("%s: %v", names[i], err)
+	}
+	return nil
+}
+
+type openAPIOperation struct {
+	doc    *OpenAPI
+	op     *OpenAPIOperation
+	params []*OpenAPIParameter
+}
+
+func (doc *OpenAPI) parameters(item *OpenAPIPathItem, op *OpenAPIOperation) []*OpenAPIParameter {
+	params := make([]*OpenAPIParameter, 0)
+	index := make(map[string]int)
+	for _, p := range append(append([]*OpenAPIParameter{}, item.Parameters...), op.Parameters...) {
+		p = doc.resolveParameter(p)
+		if p == nil {
+			continue
+		}
+		key := p.In + ":" + p.Name
+		if i, ok := index[key]; ok {
+			params[i] = p
+			continue
+		}
+		index[key] = len(params)
+		params = append(params, p)
+	}
+	return params
+}
+
+func refName(ref, prefix string) string {
+	return strings.TrimPrefix(ref, "#/components/"+prefix+"/")
+}
+
+func (doc *OpenAPI) resolveParameter(p *OpenAPIParameter) *OpenAPIParameter {
+	for i := 0; p != nil && p.Ref != "" && i < openAPIMaxDepth; i++ {
+		p = doc.Components.Parameters[refName(p.Ref, "parameters")]
+	}
+	return p
+}
+
+func (doc *OpenAPI) resolveSchema(s *OpenAPISchema) *OpenAPISchema {
+	for i := 0; s != nil && s.Ref != "" && i < openAPIMaxDepth; i++ {
+		s = doc.Components.Schemas[refName(s.Ref, "schemas")]
+	}
+	return s
+}
+
+func (doc *OpenAPI) resolveResponse(r *OpenAPIResponse) *OpenAPIResponse {
+	for i := 0; r != nil && r.Ref != "" && i < openAPIMaxDepth; i++ {
+		r = doc.Components.Responses[refName(r.Ref, "responses")]
+	}
+	return r
+}
+
+func (doc *OpenAPI) resolveRequestBody(b *OpenAPIRequestBody) *OpenAPIRequestBody {
+	for i := 0; b != nil && b.Ref != "" && i < openAPIMaxDepth; i++ {
+		b = doc.Components.RequestBodies[refName(b.Ref, "requestBodies")]
+	}
+	return b
+}
+
+func (o *openAPIOperation) exampleResponse() (*http.Response, error) {
+	codes := make([]string, 0, len(o.op.Responses))
+	for code := range o.op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("no responses defined")
+	}
+
+	chosen := codes[0]
+	for _, code := range codes {
+		if strings.HasPrefix(code, "2") {
+			chosen = code
+			break
+		}
+		if code == "default" {
+			chosen = code
+		}
+	}
+	status, err := strconv.Atoi(chosen)
+	if err != nil {
+		status = http.StatusOK
+	}
+
+	resp := o.doc.resolveResponse(o.op.Responses[chosen])
+	if resp == nil || len(resp.Content) == 0 {
+		return NewHttpResponseWithBytes(status, nil), nil
+	}
+	contentType := preferredMediaType(resp.Content)
+	media := resp.Content[contentType]
+
+	var body []byte
+	example := o.doc.mediaExample(media)
+	if s, ok := example.(string); ok && !isJSONMediaType(contentType) {
+		body = []byte(s)
+	} else if body, err = json.Marshal(normalizeYAMLValue(example)); err != nil {
+		return nil, err
+	}
+	httpResp := NewHttpResponseWithBytes(status, body)
+	httpResp.Header.Set("Content-Type", contentType)
+	return httpResp, nil
+}
+
+func preferredMediaType(content map[string]*OpenAPIMediaType) string {
+	types := make([]string, 0, len(content))
+	for contentType := range content {
+		types = append(types, contentType)
+	}
+	sort.Strings(types)
+	for _, contentType := range types {
+		if contentType == "application/json" {
+			return contentType
+		}
+	}
+	for _, contentType := range types {
+		if isJSONMediaType(contentType) {
+			return contentType
+		}
+	}
+	return types[0]
+}
+
+func isJSONMediaType(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = contentType
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+func (doc *OpenAPI) mediaExample(media *OpenAPIMediaType) interface{} {
+	if media == nil {
+		return nil
+	}
+	if media.Example != nil {
+		return media.Example
+	}
+	names := make([]string, 0, len(media.Examples))
+	for name := range media.Examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		example := media.Examples[name]
+		if example != nil && example.Ref != "" {
+			example = doc.Components.Examples[refName(example.Ref, "examples")]
+		}
+		if example != nil && example.Value != nil {
+			return example.Value
+		}
+	}
+	return doc.synthesize(media.Schema, 0)
+}
+
+func (doc *OpenAPI) synthesize(s *OpenAPISchema, depth int) interface{} {
+	s = doc.resolveSchema(s)
+	if s == nil || depth > openAPIMaxDepth {
+		return nil
+	}
+	switch {
+	case s.Example != nil:
+		return s.Example
+	case s.Default != nil:
+		return s.Default
+	case len(s.Enum) > 0:
+		return s.Enum[0]
+	case len(s.AllOf) > 0:
+		merged := make(map[string]interface{})
+		for _, sub := range s.AllOf {
+			if m, ok := doc.synthesize(sub, depth+1).(map[string]interface{}); ok {
+				for k, v := range m {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	case len(s.OneOf) > 0:
+		return doc.synthesize(s.OneOf[0], depth+1)
+	case len(s.AnyOf) > 0:
+		return doc.synthesize(s.AnyOf[0], depth+1)
+	}
+
+	switch s.schemaType() {
+	case "object":
+		obj := make(map[string]interface{}, len(s.Properties))
+		for name, prop := range s.Properties {
+			obj[name] = doc.synthesize(prop, depth+1)
+		}
+		return obj
+	case "array":
+		if depth >= openAPIMaxDepth {
+			return []interface{}{}
+		}
+		return []interface{}{doc.synthesize(s.Items, depth+1)}
+	case "integer":
+		if s.Minimum != nil {
+			return int64(math.Ceil(*s.Minimum))
+		}
+		return 0
+	case "number":
+		if s.Minimum != nil {
+			return *s.Minimum
+		}
+		return 0.0
+	case "boolean":
+		return true
+	case "string":
+		return exampleString(s.Format)
+	}
+	return nil
+}
+
+func (s *OpenAPISchema) schemaType() string {
+	if s.Type == "" && len(s.Properties) > 0 {
+		return "object"
+	}
+	return s.Type
+}
+
+func exampleString(format string) string {
+	switch format {
+	case "date":
+		return "2020-01-01"
+	case "date-time":
+		return "2020-01-01T00:00:00Z"
+	case "email":
+		return "user@example.com"
+	case "uuid":
+		return "00000000-0000-0000-0000-000000000000"
+	case "uri", "url":
+		return "https://example.com"
+	case "byte":
+		return "c3RyaW5n"
+	}
+	return "string"
+}
+
+func (o *openAPIOperation) validating(next RequestHandler) RequestHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		if errs := o.validate(req); len(errs) > 0 {
+			resp, err := NewHttpResponseWithJson(http.StatusBadRequest, map[string][]string{"errors": errs})
+			if err != nil {
+				return nil, err
+			}
+			resp.Header.Set("Content-Type", "application/json")
+			resp.Request = req
+			return resp, nil
+		}
+		return next(req)
+	}
+}
+
+func (o *openAPIOperation) validate(req *http.Request) []string {
+	errs := make([]string, 0)
+	query := req.URL.Query()
+	for _, p := range o.params {
+		var values []string
+		switch p.In {
+		case "path":
+			if v := PathParam(req, p.Name); v != "" {
+				values = []string{v}
+			}
+		case "query":
+			values = query[p.Name]
+		case "header":
+			values = req.Header.Values(p.Name)
+		case "cookie":
+			if c, err := req.Cookie(p.Name); err == nil {
+				values = []string{c.Value}
+			}
+		}
+		where := fmt.Sprintf("%s parameter '%s'", p.In, p.Name)
+		if len(values) == 0 {
+			if p.Required || p.In == "path" {
+				errs = append(errs, where+" is required")
+			}
+			continue
+		}
+		schema := o.doc.resolveSchema(p.Schema)
+		if schema == nil {
+			continue
+		}
+		value, err := coerceParameter(schema, o.doc.resolveSchema(schema.Items), values)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("%s %v", where, err))
+			continue
+		}
+		errs = append(errs, o.doc.validateValue(schema, value, where, 0)...)
+	}
+	return append(errs, o.validateBody(req)...)
+}
+
+func coerceParameter(schema, items *OpenAPISchema, values []string) (interface{}, error) {
+	if schema.schemaType() == "array" {
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+		result := make([]interface{}, 0, len(values))
+		for _, v := range values {
+			item, err := coerceScalar(items, v)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, item)
+		}
+		return result, nil
+	}
+	return coerceScalar(schema, values[0])
+}
+
+func coerceScalar(schema *OpenAPISchema, value string) (interface{}, error) {
+	if schema == nil {
+		return value, nil
+	}
+	switch schema.schemaType() {
+	case "integer", "number":
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid %s", value, schema.Type)
+		}
+		return f, nil
+	case "boolean":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid boolean", value)
+		}
+		return b, nil
+	}
+	return value, nil
+}
+
+func (o *openAPIOperation) validateBody(req *http.Request) []string {
+	body := o.doc.resolveRequestBody(o.op.RequestBody)
+	if body == nil {
+		return nil
+	}
+	data, err := peekBody(req)
+	if err != nil {
+		return []string{fmt.Sprintf("cannot read request body: %v", err)}
+	}
+	if len(data) == 0 {
+		if body.Required {
+			return []string{"request body is required"}
+		}
+		return nil
+	}
+
+	contentType := req.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return []string{fmt.Sprintf("invalid content type '%s'", contentType)}
+	}
+	media, ok := body.Content[mediaType]
+	if !ok {
+		media, ok = body.Content[strings.SplitN(mediaType, "/", 2)[0]+"/*"]
+	}
+	if !ok {
+		media, ok = body.Content["*/*"]
+	}
+	if !ok {
+		return []string{fmt.Sprintf("content type '%s' is not accepted", mediaType)}
+	}
+	if !isJSONMediaType(mediaType) || media == nil || media.Schema == nil {
+		return nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return []string{fmt.Sprintf("request body is not valid json: %v", err)}
+	}
+	return o.doc.validateValue(media.Schema, value, "request body", 0)
+}
+
+func (doc *OpenAPI) validateValue(s *OpenAPISchema, value interface{}, where string, depth int) []string {
+	s = doc.resolveSchema(s)
+	if s == nil || depth > openAPIMaxDepth*4 {
+		return nil
+	}
+	if value == nil {
+		if s.Nullable || s.schemaType() == "" {
+			return nil
+		}
+		return []string{where + " must not be null"}
+	}
+
+	errs := make([]string, 0)
+	for _, sub := range s.AllOf {
+		errs = append(errs, doc.validateValue(sub, value, where, depth+1)...)
+	}
+	if len(s.AnyOf) > 0 && doc.countValid(s.AnyOf, value, where, depth) == 0 {
+		errs = append(errs, where+" does not match any allowed schema")
+	}
+	if len(s.OneOf) > 0 && doc.countValid(s.OneOf, value, where, depth) != 1 {
+		errs = append(errs, where+" must match exactly one allowed schema")
+	}
+	if len(s.Enum) > 0 && !enumContains(s.Enum, value) {
+		errs = append(errs, fmt.Sprintf("%s must be one of %v", where, s.Enum))
+	}
+
+	switch s.schemaType() {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return append(errs, where+" must be an object")
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				errs = append(errs, fmt.Sprintf("%s.%s is required", where, name))
+			}
+		}
+		for _, name := range sortedInterfaceKeys(obj) {
+			if prop, ok := s.Properties[name]; ok {
+				errs = append(errs, doc.validateValue(prop, obj[name], where+"."+name, depth+1)...)
+			} else if allowed, ok := s.AdditionalProperties.(bool); ok && !allowed {
+				errs = append(errs, fmt.Sprintf("%s.%s is not allowed", where, name))
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			return append(errs, where+" must be an array")
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			errs = append(errs, fmt.Sprintf("%s must have at least %d items", where, *s.MinItems))
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			errs = append(errs, fmt.Sprintf("%s must have at most %d items", where, *s.MaxItems))
+		}
+		for i, item := range arr {
+			errs = append(errs, doc.validateValue(s.Items, item, fmt.Sprintf("%s[%d]", where, i), depth+1)...)
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return append(errs, where+" must be a string")
+		}
+		if s.MinLength != nil && len([]rune(str)) < *s.MinLength {
+			errs = append(errs, fmt.Sprintf("%s must be at least %d characters", where, *s.MinLength))
+		}
+		if s.MaxLength != nil && len([]rune(str)) > *s.MaxLength {
+			errs = append(errs, fmt.Sprintf("%s must be at most %d characters", where, *s.MaxLength))
+		}
+		if s.Pattern != "" {
+			if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+				errs = append(errs, fmt.Sprintf("%s must match %q", where, s.Pattern))
+			}
+		}
+	case "integer", "number":
+		num, ok := value.(float64)
+		if !ok {
+			return append(errs, fmt.Sprintf("%s must be a %s", where, s.Type))
+		}
+		if s.Type == "integer" && num != math.Trunc(num) {
+			return append(errs, where+" must be an integer")
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			errs = append(errs, fmt.Sprintf("%s must be at least %v", where, *s.Minimum))
+		}
+		if s.Maximum != nil && num > *s.Maximum {
+			errs = append(errs, fmt.Sprintf("%s must be at most %v", where, *s.Maximum))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			errs = append(errs, where+" must be a boolean")
+		}
+	}
+	return errs
+}
+
+func (doc *OpenAPI) countValid(schemas []*OpenAPISchema, value interface{}, where string, depth int) int {
+	valid := 0
+	for _, sub := range schemas {
+		if len(doc.validateValue(sub, value, where, depth+1)) == 0 {
+			valid++
+		}
+	}
+	return valid
+}
+
+func enumContains(enum []interface{}, value interface{}) bool {
+	for _, allowed := range enum {
+		normalized, err := toJSONValue(normalizeYAMLValue(allowed))
+		if err == nil && reflect.DeepEqual(normalized, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,246 @@
+package easymock
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const redactedValue = "REDACTED"
+
+type Cassette struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method   string      `json:"method"`
+	URL      string      `json:"url"`
+	Header   http.Header `json:"header,omitempty"`
+	Body     []byte      `json:"body,omitempty"`
+	BodyHash string      `json:"body_hash"`
+}
+
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       []byte      `json:"body,omitempty"`
+}
+
+type RecordOptions struct {
+	RedactHeaders []string
+}
+
+type ReplayOptions struct {
+	MatchBody    bool
+	MatchHeaders []string
+}
+
+type recorder struct {
+	mu       sync.Mutex
+	redact   *StringSet
+	cassette *Cassette
+}
+
+func LoadCassette(path string) (*Cassette, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cassette := &Cassette{}
+	if err := json.Unmarshal(b, cassette); err != nil {
+		return nil, fmt.Errorf("invalid cassette '%s': %v", path, err)
+	}
+	return cassette, nil
+}
+
+func (c *Cassette) Save(path string) error {
+	b, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// StartRecording forwards every request to the original transport and
+// captures it into a cassette until StopRecording is called.
+func (mocker *EasyMocker) StartRecording(opts RecordOptions) {
+	redact := make([]string, 0, len(opts.RedactHeaders))
+	for _, name := range opts.RedactHeaders {
+		redact = append(redact, http.CanonicalHeaderKey(name))
+	}
+	mocker.responderMu.Lock()
+	mocker.recorder = &recorder{
+		redact:   CreateStringSet(redact),
+		cassette: &Cassette{Interactions: make([]Interaction, 0)},
+	}
+	mocker.responderMu.Unlock()
+}
+
+func (mocker *EasyMocker) StopRecording() *Cassette {
+	mocker.responderMu.Lock()
+	rec := mocker.recorder
+	mocker.recorder = nil
+	mocker.responderMu.Unlock()
+	if rec == nil {
+		return &Cassette{Interactions: make([]Interaction, 0)}
+	}
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	return rec.cassette
+}
+
+func (mocker *EasyMocker) activeRecorder() *recorder {
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+	return mocker.recorder
+}
+
+func (mocker *EasyMocker) recordRoundTrip(rec *recorder, req *http.Request, entry *JournalEntry) (*http.Response, error) {
+	entry.Route = "(recorded)"
+	resp, err := mocker.origin(req).RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	rec.mu.Lock()
+	rec.cassette.Interactions = append(rec.cassette.Interactions, Interaction{
+		Request: RecordedRequest{
+			Method:   entry.Method,
+			URL:      entry.URL,
+			Header:   rec.redactHeader(entry.Header),
+			Body:     entry.Body,
+			BodyHash: bodyHash(entry.Body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Header:     rec.redactHeader(resp.Header),
+			Body:       body,
+		},
+	})
+	rec.mu.Unlock()
+	return resp, nil
+}
+
+func (rec *recorder) redactHeader(header http.Header) http.Header {
+	redacted := header.Clone()
+	for name, values := range redacted {
+		if rec.redact.Contains(name) {
+			for i := range values {
+				values[i] = redactedValue
+			}
+		}
+	}
+	return redacted
+}
+
+// Replay registers a responder for every interaction of the cassette.
+// Interactions that share a request are answered in their recorded order.
+// Nothing is registered if one of the routes already exists.
+func (mocker *EasyMocker) Replay(cassette *Cassette, opts ReplayOptions) error {
+	type replayGroup struct {
+		req       RecordedRequest
+		responses []*http.Response
+	}
+	groups := make([]*replayGroup, 0)
+	index := make(map[string]*replayGroup)
+
+	for i, interaction := range cassette.Interactions {
+		req := interaction.Request
+		base, query, err := parseAndNormalizeUrl(req.URL)
+		if err != nil {
+			return fmt.Errorf("interaction %d has an invalid url '%s': %v", i, req.URL, err)
+		}
+		hash := req.BodyHash
+		if hash == "" {
+			hash = bodyHash(req.Body)
+			req.BodyHash = hash
+		}
+		parts := []string{req.Method, normalizedRouteUrl(base, query, QueryExact)}
+		if opts.MatchBody {
+			parts = append(parts, hash)
+		}
+		for _, name := range opts.MatchHeaders {
+			parts = append(parts, name+"="+strings.Join(req.Header.Values(name), ","))
+		}
+		key := strings.Join(parts, "\n")
+
+		group, ok := index[key]
+		if !ok {
+			group = &replayGroup{req: req}
+			index[key] = group
+			groups = append(groups, group)
+		}
+		group.responses = append(group.responses, interaction.Response.toHttpResponse())
+	}
+
+	routes := make([]*route, 0, len(groups))
+	for _, group := range groups {
+		responder := NewSequenceEasyResponder(RepeatLast, group.responses...)
+		matchers := make([]Matcher, 0)
+		if opts.MatchBody {
+			matchers = append(matchers, bodyHashEquals(group.req.BodyHash))
+		}
+		for _, name := range opts.MatchHeaders {
+			matchers = append(matchers, headerValuesEqual(name, group.req.Header.Values(name)))
+		}
+		if len(matchers) > 0 {
+			responder.When(matchers...)
+		}
+		r, err := newExactRoute(group.req.Method, group.req.URL, QueryExact, responder)
+		if err != nil {
+			return err
+		}
+		routes = append(routes, r)
+	}
+	_, err := mocker.addRoutes(routes)
+	return err
+}
+
+func (rr RecordedResponse) toHttpResponse() *http.Response {
+	resp := NewHttpResponseWithBytes(rr.StatusCode, rr.Body)
+	for name, values := range rr.Header {
+		if name == "Content-Length" {
+			continue
+		}
+		for _, value := range values {
+			resp.Header.Add(name, value)
+		}
+	}
+	return resp
+}
+
+func bodyHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+func bodyHashEquals(hash string) Matcher {
+	return NewMatcher("body sha256 == "+hash, func(req *http.Request) bool {
+		body, err := peekBody(req)
+		return err == nil && bodyHash(body) == hash
+	})
+}
+
+func headerValuesEqual(name string, values []string) Matcher {
+	expected := strings.Join(values, ",")
+	return NewMatcher(fmt.Sprintf("header %s == %q", name, expected), func(req *http.Request) bool {
+		return strings.Join(req.Header.Values(name), ",") == expected
+	})
+}
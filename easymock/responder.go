@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"regexp"
 	"sync"
+	"time"
 )
 
 type RequestHandler func(req *http.Request) (resp *http.Response, err error)
@@ -14,6 +15,14 @@ type EasyResponder struct {
 	mu         sync.Mutex
 	reqHandler RequestHandler
 	available  bool
+	matchers   []Matcher
+	maxUses    int
+	uses       int
+	deadline   time.Time
+	delay      DelayFunc
+	chaos      *chaos
+
+	scenario, requiredState, newState string
 }
 
 func NewStringEasyResponder(statusCode int, respBody string) *EasyResponder {
@@ -47,14 +56,7 @@ func NewXmlEasyResponder(statusCode int, respBody interface{}) (*EasyResponder,
 }
 
 func NewEasyResponderWithResp(resp *http.Response) *EasyResponder {
-	reqHandler := func(req *http.Request) (*http.Response, error) {
-		res := *resp
-		if body, ok := resp.Body.(*easyResponse); ok {
-			res.Body = body.Clone()
-		}
-		res.Request = req
-		return &res, nil
-	}
+	reqHandler := cloneRespHandler(resp)
 
 	responder := &EasyResponder{
 		mu:         sync.Mutex{},
@@ -64,6 +66,17 @@ func NewEasyResponderWithResp(resp *http.Response) *EasyResponder {
 	return responder
 }
 
+func cloneRespHandler(resp *http.Response) RequestHandler {
+	return func(req *http.Request) (*http.Response, error) {
+		res := *resp
+		if body, ok := resp.Body.(*easyResponse); ok {
+			res.Body = body.Clone()
+		}
+		res.Request = req
+		return &res, nil
+	}
+}
+
 func NewEasyResponderWithReqHandler(reqHandler RequestHandler) *EasyResponder {
 	return &EasyResponder{
 		mu:         sync.Mutex{},
@@ -87,13 +100,81 @@ func (eR *EasyResponder) Disable() {
 func (eR *EasyResponder) IsAvailable() bool {
 	eR.mu.Lock()
 	defer eR.mu.Unlock()
-	return eR.available
+	return eR.isAvailableLocked()
+}
+
+func (eR *EasyResponder) When(matchers ...Matcher) *EasyResponder {
+	eR.mu.Lock()
+	eR.matchers = append(eR.matchers, matchers...)
+	eR.mu.Unlock()
+	return eR
+}
+
+func (eR *EasyResponder) Matchers() []Matcher {
+	eR.mu.Lock()
+	defer eR.mu.Unlock()
+	matchers := make([]Matcher, len(eR.matchers))
+	copy(matchers, eR.matchers)
+	return matchers
+}
+
+func (eR *EasyResponder) isConditional() bool {
+	eR.mu.Lock()
+	defer eR.mu.Unlock()
+	return len(eR.matchers) > 0 || eR.isLimitedLocked() || eR.requiredState != ""
+}
+
+func (eR *EasyResponder) matches(req *http.Request) bool {
+	for _, matcher := range eR.Matchers() {
+		if !matcher.Match(req) {
+			return false
+		}
+	}
+	return true
 }
 
 type EasyRegexResponder struct {
 	*EasyResponder
-	oriUrl  string
-	matcher *regexp.Regexp
+	oriUrl   string
+	matcher  *regexp.Regexp
+	priority int
+}
+
+func (eRR *EasyRegexResponder) SetPriority(priority int) {
+	eRR.mu.Lock()
+	eRR.priority = priority
+	eRR.mu.Unlock()
+}
+
+func (eRR *EasyRegexResponder) Priority() int {
+	eRR.mu.Lock()
+	defer eRR.mu.Unlock()
+	return eRR.priority
+}
+
+func (eRR *EasyRegexResponder) When(matchers ...Matcher) *EasyRegexResponder {
+	eRR.EasyResponder.When(matchers...)
+	return eRR
+}
+
+func (eRR *EasyRegexResponder) Once() *EasyRegexResponder {
+	eRR.EasyResponder.Once()
+	return eRR
+}
+
+func (eRR *EasyRegexResponder) Times(n int) *EasyRegexResponder {
+	eRR.EasyResponder.Times(n)
+	return eRR
+}
+
+func (eRR *EasyRegexResponder) ExpireAt(deadline time.Time) *EasyRegexResponder {
+	eRR.EasyResponder.ExpireAt(deadline)
+	return eRR
+}
+
+func (eRR *EasyRegexResponder) ExpireAfter(d time.Duration) *EasyRegexResponder {
+	eRR.EasyResponder.ExpireAfter(d)
+	return eRR
 }
 
 func (eRR *EasyRegexResponder) isMatched(url string) bool {
@@ -45,6 +45,16 @@ func (er *easyResponse) Clone() *easyResponse {
 	}
 }
 
+func (er *easyResponse) bytes() []byte {
+	switch d := er.body.(type) {
+	case string:
+		return []byte(d)
+	case []byte:
+		return append([]byte(nil), d...)
+	}
+	return nil
+}
+
 func newEasyResponse(data interface{}) *easyResponse {
 	eResp := &easyResponse{
 		body: data,
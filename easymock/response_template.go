@@ -0,0 +1,161 @@
+package easymock
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// TemplateData is the value templates of a template responder are executed
+// with, e.g. {"id": "{{.PathParam "id"}}", "at": "{{.Now.Unix}}"}.
+type TemplateData struct {
+	RequestID string
+	Now       time.Time
+
+	req *http.Request
+}
+
+func (td *TemplateData) Method() string {
+	return td.req.Method
+}
+
+func (td *TemplateData) URL() string {
+	return td.req.URL.String()
+}
+
+func (td *TemplateData) Path() string {
+	return td.req.URL.Path
+}
+
+func (td *TemplateData) PathParam(name string) string {
+	return PathParam(td.req, name)
+}
+
+func (td *TemplateData) Query(name string) string {
+	return td.req.URL.Query().Get(name)
+}
+
+func (td *TemplateData) QueryValues(name string) []string {
+	return td.req.URL.Query()[name]
+}
+
+func (td *TemplateData) Header(name string) string {
+	return td.req.Header.Get(name)
+}
+
+func (td *TemplateData) Cookie(name string) string {
+	if cookie, err := td.req.Cookie(name); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+func (td *TemplateData) Body() string {
+	body, _ := peekBody(td.req)
+	return string(body)
+}
+
+// JSON returns the field of the json request body found at path, using the
+// same path syntax as JSONBodyFieldEquals, or nil if there is none.
+func (td *TemplateData) JSON(path string) interface{} {
+	value, _ := lookupJSONBody(td.req, path)
+	return value
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(v interface{}) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+	"default": func(fallback, v interface{}) interface{} {
+		if v == nil || v == "" {
+			return fallback
+		}
+		return v
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	"trim":  strings.TrimSpace,
+}
+
+func parseResponseTemplate(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+}
+
+func NewTemplateEasyResponder(statusCode int, bodyTmpl string, headerTmpls map[string]string) (*EasyResponder, error) {
+	resp := NewHttpResponseWithString(statusCode, bodyTmpl)
+	for name, value := range headerTmpls {
+		resp.Header.Set(name, value)
+	}
+	handler, err := templateRespHandler(resp)
+	if err != nil {
+		return nil, err
+	}
+	return NewEasyResponderWithReqHandler(handler), nil
+}
+
+func NewTemplateEasyRegexResponder(statusCode int, bodyTmpl string, headerTmpls map[string]string) (*EasyRegexResponder, error) {
+	responder, err := NewTemplateEasyResponder(statusCode, bodyTmpl, headerTmpls)
+	if err != nil {
+		return nil, err
+	}
+	return &EasyRegexResponder{EasyResponder: responder}, nil
+}
+
+// templateRespHandler treats the body and header values of resp as
+// templates and renders them for every request.
+func templateRespHandler(resp *http.Response) (RequestHandler, error) {
+	var body []byte
+	if er, ok := resp.Body.(*easyResponse); ok {
+		body = er.bytes()
+	}
+	bodyTmpl, err := parseResponseTemplate("body", string(body))
+	if err != nil {
+		return nil, err
+	}
+	headerTmpls := make(map[string][]*template.Template, len(resp.Header))
+	for name, values := range resp.Header {
+		for _, value := range values {
+			tmpl, err := parseResponseTemplate(name, value)
+			if err != nil {
+				return nil, err
+			}
+			headerTmpls[name] = append(headerTmpls[name], tmpl)
+		}
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		data := &TemplateData{RequestID: newRequestID(), Now: time.Now(), req: req}
+		var buf bytes.Buffer
+		if err := bodyTmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("easymock: rendering response body: %v", err)
+		}
+		rendered := NewHttpResponseWithBytes(resp.StatusCode, buf.Bytes())
+		for name, tmpls := range headerTmpls {
+			for _, tmpl := range tmpls {
+				var value bytes.Buffer
+				if err := tmpl.Execute(&value, data); err != nil {
+					return nil, fmt.Errorf("easymock: rendering response header %s: %v", name, err)
+				}
+				rendered.Header.Add(name, value.String())
+			}
+		}
+		rendered.Request = req
+		return rendered, nil
+	}, nil
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	id := hex.EncodeToString(b)
+	return id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:20] + "-" + id[20:]
+}
@@ -0,0 +1,275 @@
+package easymock
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+type routeKind int
+
+const (
+	exactRouteKind routeKind = iota
+	regexRouteKind
+	templateRouteKind
+)
+
+func (kind routeKind) String() string {
+	switch kind {
+	case regexRouteKind:
+		return "regex"
+	case templateRouteKind:
+		return "template"
+	}
+	return "exact"
+}
+
+type routeKey struct {
+	kind routeKind
+	rt   router
+}
+
+func (key routeKey) String() string {
+	switch key.kind {
+	case regexRouteKind:
+		return key.rt.String() + " (regex)"
+	case templateRouteKind:
+		return key.rt.String() + " (template)"
+	}
+	return key.rt.String()
+}
+
+type route struct {
+	key            routeKey
+	responder      *EasyResponder
+	base           string
+	query          url.Values
+	mode           QueryMatch
+	pattern        *regexp.Regexp
+	names          []string
+	regexResponder *EasyRegexResponder
+	seq            int
+	id             string
+	source         string
+}
+
+type routeCandidate struct {
+	*route
+	params map[string]string
+}
+
+func (r *route) String() string {
+	if r.key.kind == exactRouteKind && r.mode != QueryExact {
+		return r.key.String() + " (" + r.mode.String() + ")"
+	}
+	return r.key.String()
+}
+
+func (r *route) rank() int {
+	switch r.key.kind {
+	case exactRouteKind:
+		return int(r.mode)
+	case regexRouteKind:
+		return -r.regexResponder.Priority()
+	}
+	return 0
+}
+
+func (r *route) matchUrl(rawUrl, base string, query url.Values) (map[string]string, bool) {
+	switch r.key.kind {
+	case regexRouteKind:
+		return nil, r.regexResponder.isMatched(rawUrl)
+	case templateRouteKind:
+		return matchTemplate(r.pattern, r.names, base)
+	}
+	return nil, r.base == base && queryMatched(r.query, query, r.mode)
+}
+
+func newExactRoute(method, url string, mode QueryMatch, responder *EasyResponder) (*route, error) {
+	base, query, err := parseAndNormalizeUrl(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url '%s': %v", url, err)
+	}
+	return &route{
+		key: routeKey{
+			kind: exactRouteKind,
+			rt:   router{Method: method, Url: normalizedRouteUrl(base, query, mode)},
+		},
+		responder: responder,
+		base:      base,
+		query:     query,
+		mode:      mode,
+	}, nil
+}
+
+func newRegexRoute(method, url string, regexResponder *EasyRegexResponder) (*route, error) {
+	matcher, err := regexp.Compile(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex '%s': %v", url, err)
+	}
+	regexResponder.oriUrl = url
+	regexResponder.matcher = matcher
+	return &route{
+		key: routeKey{
+			kind: regexRouteKind,
+			rt:   router{Method: method, Url: url},
+		},
+		responder:      regexResponder.EasyResponder,
+		regexResponder: regexResponder,
+	}, nil
+}
+
+func newTemplateRoute(method, template string, responder *EasyResponder) (*route, error) {
+	matcher, names, err := compileTemplate(template)
+	if err != nil {
+		return nil, err
+	}
+	return &route{
+		key: routeKey{
+			kind: templateRouteKind,
+			rt:   router{Method: method, Url: template},
+		},
+		responder: responder,
+		pattern:   matcher,
+		names:     names,
+	}, nil
+}
+
+func (mocker *EasyMocker) addRoute(r *route) error {
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+	return mocker.addRouteLocked(r)
+}
+
+// addRoutes adds either all of routes or, on the first conflict, none. The
+// index of the conflicting route is returned with the error.
+func (mocker *EasyMocker) addRoutes(routes []*route) (int, error) {
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+
+	registered, seq := len(mocker.routes), mocker.routeSeq
+	for i, r := range routes {
+		if err := mocker.addRouteLocked(r); err != nil {
+			mocker.routes, mocker.routeSeq = mocker.routes[:registered], seq
+			return i, err
+		}
+	}
+	return 0, nil
+}
+
+func (mocker *EasyMocker) addRouteLocked(r *route) error {
+	for _, existing := range mocker.routes {
+		if r.id != "" && existing.id == r.id {
+			return fmt.Errorf(routeIDExistsTmpl, r.id)
+		}
+		if existing.key == r.key && existing.mode == r.mode &&
+			!existing.responder.isConditional() && !r.responder.isConditional() {
+			return fmt.Errorf(routeExistsTmpl, r.key.rt.Method, r.key.rt.Url)
+		}
+	}
+	mocker.routeSeq++
+	r.seq = mocker.routeSeq
+	if r.id == "" {
+		r.id = strconv.Itoa(r.seq)
+	}
+	mocker.routes = append(mocker.routes, r)
+	return nil
+}
+
+func (mocker *EasyMocker) findRouteByID(id string) (*route, bool) {
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+
+	for _, r := range mocker.routes {
+		if r.id == id {
+			return r, true
+		}
+	}
+	return nil, false
+}
+
+// RemoveRoute removes the single route with the given id, leaving other
+// responders stacked on the same method and url in place.
+func (mocker *EasyMocker) RemoveRoute(id string) bool {
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+
+	for i, r := range mocker.routes {
+		if r.id == id {
+			mocker.routes = append(mocker.routes[:i:i], mocker.routes[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (mocker *EasyMocker) removeRoutes(key routeKey) {
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+
+	routes := make([]*route, 0, len(mocker.routes))
+	for _, r := range mocker.routes {
+		if r.key != key {
+			routes = append(routes, r)
+		}
+	}
+	mocker.routes = routes
+}
+
+func (mocker *EasyMocker) findRoute(req *http.Request, method string) (*route, map[string]string, bool) {
+	mocker.responderMu.Lock()
+	routes := make([]*route, len(mocker.routes))
+	copy(routes, mocker.routes)
+	mocker.responderMu.Unlock()
+
+	rawUrl := req.URL.String()
+	base, query := normalizeUrl(req.URL)
+
+	var disabled *routeCandidate
+	for _, kind := range []routeKind{exactRouteKind, templateRouteKind, regexRouteKind} {
+		candidates := make([]routeCandidate, 0)
+		for _, r := range routes {
+			if r.key.kind != kind || r.key.rt.Method != method {
+				continue
+			}
+			if params, ok := r.matchUrl(rawUrl, base, query); ok {
+				candidates = append(candidates, routeCandidate{route: r, params: params})
+			}
+		}
+		sortCandidates(candidates)
+
+		for i := range candidates {
+			candidate := &candidates[i]
+			if !candidate.responder.matches(req) || !mocker.inRequiredState(candidate.responder) {
+				continue
+			}
+			if candidate.responder.IsAvailable() {
+				return candidate.route, candidate.params, true
+			}
+			if disabled == nil {
+				disabled = candidate
+			}
+		}
+	}
+	if disabled != nil {
+		return disabled.route, disabled.params, true
+	}
+	return nil, nil, false
+}
+
+func sortCandidates(candidates []routeCandidate) {
+	sort.SliceStable(candidates, func(i, j int) bool {
+		ri, rj := candidates[i].rank(), candidates[j].rank()
+		if ri != rj {
+			return ri < rj
+		}
+		ci, cj := candidates[i].responder.isConditional(), candidates[j].responder.isConditional()
+		if ci != cj {
+			return ci
+		}
+		return candidates[i].seq < candidates[j].seq
+	})
+}
@@ -0,0 +1,105 @@
+package easymock
+
+const ScenarioStarted = "Started"
+
+// InScenario ties the responder to the named scenario. Together with
+// WhenState and WillSetStateTo it lets responders on different routes
+// model a flow, every scenario starts in ScenarioStarted.
+func (eR *EasyResponder) InScenario(name string) *EasyResponder {
+	eR.mu.Lock()
+	eR.scenario = name
+	eR.mu.Unlock()
+	return eR
+}
+
+func (eR *EasyResponder) WhenState(state string) *EasyResponder {
+	eR.mu.Lock()
+	eR.requiredState = state
+	eR.mu.Unlock()
+	return eR
+}
+
+func (eR *EasyResponder) WillSetStateTo(state string) *EasyResponder {
+	eR.mu.Lock()
+	eR.newState = state
+	eR.mu.Unlock()
+	return eR
+}
+
+func (eRR *EasyRegexResponder) InScenario(name string) *EasyRegexResponder {
+	eRR.EasyResponder.InScenario(name)
+	return eRR
+}
+
+func (eRR *EasyRegexResponder) WhenState(state string) *EasyRegexResponder {
+	eRR.EasyResponder.WhenState(state)
+	return eRR
+}
+
+func (eRR *EasyRegexResponder) WillSetStateTo(state string) *EasyRegexResponder {
+	eRR.EasyResponder.WillSetStateTo(state)
+	return eRR
+}
+
+func (eR *EasyResponder) scenarioStates() (scenario, required, next string) {
+	eR.mu.Lock()
+	defer eR.mu.Unlock()
+	return eR.scenario, eR.requiredState, eR.newState
+}
+
+func (mocker *EasyMocker) ScenarioState(name string) string {
+	mocker.scenarioMu.Lock()
+	defer mocker.scenarioMu.Unlock()
+	return mocker.scenarioStateLocked(name)
+}
+
+func (mocker *EasyMocker) scenarioStateLocked(name string) string {
+	if state, ok := mocker.scenarios[name]; ok {
+		return state
+	}
+	return ScenarioStarted
+}
+
+// Scenarios returns the state of every scenario that has left its
+// initial state.
+func (mocker *EasyMocker) Scenarios() map[string]string {
+	mocker.scenarioMu.Lock()
+	defer mocker.scenarioMu.Unlock()
+
+	scenarios := make(map[string]string, len(mocker.scenarios))
+	for name, state := range mocker.scenarios {
+		scenarios[name] = state
+	}
+	return scenarios
+}
+
+func (mocker *EasyMocker) SetScenarioState(name, state string) {
+	mocker.scenarioMu.Lock()
+	mocker.scenarios[name] = state
+	mocker.scenarioMu.Unlock()
+}
+
+func (mocker *EasyMocker) ResetScenarios() {
+	mocker.scenarioMu.Lock()
+	mocker.scenarios = make(map[string]string)
+	mocker.scenarioMu.Unlock()
+}
+
+func (mocker *EasyMocker) inRequiredState(responder *EasyResponder) bool {
+	scenario, required, _ := responder.scenarioStates()
+	return scenario == "" || required == "" || mocker.ScenarioState(scenario) == required
+}
+
+// transition moves the scenario of responder to its next state, unless a
+// concurrent request already moved it away from the required state.
+func (mocker *EasyMocker) transition(responder *EasyResponder) {
+	scenario, required, next := responder.scenarioStates()
+	if scenario == "" || next == "" {
+		return
+	}
+	mocker.scenarioMu.Lock()
+	if required == "" || mocker.scenarioStateLocked(scenario) == required {
+		mocker.scenarios[scenario] = next
+	}
+	mocker.scenarioMu.Unlock()
+}
@@ -0,0 +1,52 @@
+package easymock
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+)
+
+type SequenceExhausted int
+
+const (
+	RepeatLast SequenceExhausted = iota
+	WrapAround
+	FailWhenExhausted
+)
+
+var sequenceExhaustedTmpl = `all %d responses of the sequence have been used`
+
+func NewSequenceEasyResponder(exhausted SequenceExhausted, responses ...*http.Response) *EasyResponder {
+	handlers := make([]RequestHandler, 0, len(responses))
+	for _, resp := range responses {
+		handlers = append(handlers, cloneRespHandler(resp))
+	}
+	return NewSequenceEasyResponderWithReqHandlers(exhausted, handlers...)
+}
+
+func NewSequenceEasyResponderWithReqHandlers(exhausted SequenceExhausted, handlers ...RequestHandler) *EasyResponder {
+	if len(handlers) == 0 {
+		panic("sequence responder requires at least one response")
+	}
+
+	mu := sync.Mutex{}
+	next := 0
+	return NewEasyResponderWithReqHandler(func(req *http.Request) (*http.Response, error) {
+		mu.Lock()
+		i := next
+		if i >= len(handlers) {
+			switch exhausted {
+			case WrapAround:
+				i = 0
+			case FailWhenExhausted:
+				mu.Unlock()
+				return nil, fmt.Errorf(sequenceExhaustedTmpl, len(handlers))
+			default:
+				i = len(handlers) - 1
+			}
+		}
+		next = i + 1
+		mu.Unlock()
+		return handlers[i](req)
+	})
+}
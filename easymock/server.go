@@ -0,0 +1,111 @@
+package easymock
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+var _ http.Handler = (*EasyMocker)(nil)
+
+// ServeHTTP answers requests arriving over the network with the registered
+// responders. Proxy-style requests keep their absolute url, others are
+// matched against the Host header of the request. Paths under AdminPrefix
+// are answered by the admin api if it has been enabled with EnableAdmin.
+func (mocker *EasyMocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mocker.serve(w, r, nil)
+}
+
+// Handler serves every request as if it had been sent to baseURL, so that
+// clients only have to swap the host of the mocked service for the address
+// of the server.
+func (mocker *EasyMocker) Handler(baseURL string) (http.Handler, error) {
+	base, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, err
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("base url '%s' is not absolute", baseURL)
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mocker.serve(w, r, base)
+	}), nil
+}
+
+func (mocker *EasyMocker) NewServer() *httptest.Server {
+	return httptest.NewServer(mocker)
+}
+
+func (mocker *EasyMocker) NewTLSServer() *httptest.Server {
+	return httptest.NewTLSServer(mocker)
+}
+
+func (mocker *EasyMocker) serve(w http.ResponseWriter, r *http.Request, base *url.URL) {
+	if isAdminRequest(r) && mocker.adminEnabled() {
+		mocker.serveAdmin(w, r)
+		return
+	}
+	if r.Method == http.MethodConnect {
+		http.Error(w, "easymock: CONNECT is not supported", http.StatusNotImplemented)
+		return
+	}
+
+	resp, err := mocker.RoundTrip(outgoingRequest(r, base))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	header := w.Header()
+	for name, values := range resp.Header {
+		header[name] = append([]string(nil), values...)
+	}
+	if resp.ContentLength > 0 && header.Get("Content-Length") == "" {
+		header.Set("Content-Length", strconv.FormatInt(resp.ContentLength, 10))
+	}
+	w.WriteHeader(resp.StatusCode)
+	if resp.Body == nil {
+		return
+	}
+	defer resp.Body.Close()
+	flusher, _ := w.(http.Flusher)
+	if flusher != nil {
+		flusher.Flush()
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		// break the connection the way a failing upstream would
+		if flusher != nil {
+			flusher.Flush()
+		}
+		panic(http.ErrAbortHandler)
+	}
+}
+
+func outgoingRequest(r *http.Request, base *url.URL) *http.Request {
+	req := r.Clone(r.Context())
+	req.RequestURI = ""
+	u := *r.URL
+	switch {
+	case base != nil:
+		u.Scheme, u.Host = base.Scheme, base.Host
+		u.Path = strings.TrimSuffix(base.Path, "/") + r.URL.Path
+		if r.URL.RawPath != "" {
+			u.RawPath = strings.TrimSuffix(base.EscapedPath(), "/") + r.URL.RawPath
+		}
+	case !r.URL.IsAbs():
+		u.Scheme = "http"
+		if r.TLS != nil {
+			u.Scheme = "https"
+		}
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+			u.Scheme = proto
+		}
+		u.Host = r.Host
+	}
+	req.URL = &u
+	req.Host = u.Host
+	return req
+}
@@ -0,0 +1,159 @@
+package easymock
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+type pathParamsKey struct{}
+
+func RegisterTemplateResponder(method, template string, responder *EasyResponder) {
+	MockerTransport.RegisterTemplateResponder(method, template, responder)
+}
+
+func RemoveTemplateResponder(method, template string) {
+	MockerTransport.RemoveTemplateResponder(method, template)
+}
+
+// RegisterTemplateResponder registers a route such as
+// https://api.example.com/users/{id}/orders/{orderID}, where every {name}
+// matches one path segment and {name:regex} matches the given regex.
+// Requests are matched on their normalized URL without the query string.
+func (mocker *EasyMocker) RegisterTemplateResponder(method, template string, responder *EasyResponder) {
+	registerFailed(mocker.registerTemplate(method, template, responder))
+}
+
+func (mocker *EasyMocker) registerTemplate(method, template string, responder *EasyResponder) error {
+	r, err := newTemplateRoute(method, template, responder)
+	if err != nil {
+		return err
+	}
+	return mocker.addRoute(r)
+}
+
+func (mocker *EasyMocker) RemoveTemplateResponder(method, template string) {
+	mocker.removeRoutes(routeKey{kind: templateRouteKind, rt: router{Method: method, Url: template}})
+}
+
+func matchTemplate(pattern *regexp.Regexp, names []string, target string) (map[string]string, bool) {
+	groups := pattern.FindStringSubmatch(target)
+	if groups == nil {
+		return nil, false
+	}
+	params := make(map[string]string, len(names))
+	for i, name := range names {
+		value, err := url.PathUnescape(groups[i+1])
+		if err != nil {
+			value = groups[i+1]
+		}
+		params[name] = value
+	}
+	return params, true
+}
+
+func compileTemplate(template string) (*regexp.Regexp, []string, error) {
+	var pattern strings.Builder
+	names := make([]string, 0)
+	pattern.WriteString("^")
+	template = strings.TrimRight(normalizeTemplateOrigin(template), "/")
+
+	for i := 0; i < len(template); {
+		if template[i] != '{' {
+			end := strings.IndexByte(template[i:], '{')
+			if end < 0 {
+				end = len(template) - i
+			}
+			pattern.WriteString(regexp.QuoteMeta(template[i : i+end]))
+			i += end
+			continue
+		}
+
+		depth, end := 0, -1
+		for j := i; j < len(template); j++ {
+			if template[j] == '{' {
+				depth++
+			} else if template[j] == '}' {
+				depth--
+				if depth == 0 {
+					end = j
+					break
+				}
+			}
+		}
+		if end < 0 {
+			return nil, nil, fmt.Errorf("template '%s' has an unclosed '{'", template)
+		}
+
+		name, expr := template[i+1:end], `[^/]+`
+		if colon := strings.IndexByte(name, ':'); colon >= 0 {
+			name, expr = name[:colon], name[colon+1:]
+		}
+		if name == "" {
+			return nil, nil, fmt.Errorf("template '%s' has an unnamed parameter", template)
+		}
+		for _, existing := range names {
+			if existing == name {
+				return nil, nil, fmt.Errorf("template '%s' has duplicated parameter '%s'", template, name)
+			}
+		}
+		names = append(names, name)
+		pattern.WriteString("(" + expr + ")")
+		i = end + 1
+	}
+	pattern.WriteString("$")
+
+	matcher, err := regexp.Compile(pattern.String())
+	if err != nil {
+		return nil, nil, fmt.Errorf("template '%s' is invalid: %v", template, err)
+	}
+	if matcher.NumSubexp() != len(names) {
+		return nil, nil, fmt.Errorf("template '%s' must not use capturing groups in parameter patterns", template)
+	}
+	return matcher, names, nil
+}
+
+// normalizeTemplateOrigin normalizes the scheme and host of a template the
+// way normalizeUrl does for requests, hosts with parameters are kept as is.
+func normalizeTemplateOrigin(template string) string {
+	sep := strings.Index(template, "://")
+	if sep < 0 || strings.ContainsAny(template[:sep], "/{") {
+		return template
+	}
+	scheme := strings.ToLower(template[:sep])
+	rest := template[sep+3:]
+	end := strings.IndexByte(rest, '/')
+	if end < 0 {
+		end = len(rest)
+	}
+	host, path := rest[:end], rest[end:]
+	if strings.ContainsAny(host, "{}") {
+		return scheme + "://" + host + path
+	}
+	host = strings.ToLower(host)
+	if port := defaultPorts[scheme]; port != "" {
+		host = strings.TrimSuffix(host, ":"+port)
+	}
+	return scheme + "://" + host + path
+}
+
+func withPathParams(req *http.Request, params map[string]string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), pathParamsKey{}, params))
+}
+
+func PathParams(req *http.Request) map[string]string {
+	params, _ := req.Context().Value(pathParamsKey{}).(map[string]string)
+	copied := make(map[string]string, len(params))
+	for name, value := range params {
+		copied[name] = value
+	}
+	return copied
+}
+
+func PathParam(req *http.Request, name string) string {
+	params, _ := req.Context().Value(pathParamsKey{}).(map[string]string)
+	return params[name]
+}
@@ -0,0 +1,46 @@
+package easymock
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func New(t testing.TB) *EasyMocker {
+	mocker := NewEasyMockerTransport()
+	mocker.t = t
+	mocker.Start()
+	t.Cleanup(mocker.Shutdown)
+	return mocker
+}
+
+func (mocker *EasyMocker) Routes() []string {
+	mocker.responderMu.Lock()
+	defer mocker.responderMu.Unlock()
+
+	routes := make([]string, 0, len(mocker.routes))
+	for _, route := range mocker.routes {
+		routes = append(routes, route.String())
+	}
+	sort.Strings(routes)
+	return routes
+}
+
+func (mocker *EasyMocker) reportUnmatched(req *http.Request, method string) {
+	if mocker.t == nil {
+		return
+	}
+	routes := mocker.Routes()
+	registered := "  (none)"
+	if len(routes) > 0 {
+		registered = "  " + strings.Join(routes, "\n  ")
+	}
+	mocker.t.Errorf("easymock: unmatched request %s %s\nregistered routes:\n%s",
+		method, req.URL.String(), registered)
+}
+
+func (rt router) String() string {
+	return fmt.Sprintf("%s %s", rt.Method, rt.Url)
+}
@@ -1,6 +1,9 @@
 package easymock
 
-import "sync"
+import (
+	"sort"
+	"sync"
+)
 
 type StringSet struct {
 	mu sync.RWMutex
@@ -36,3 +39,21 @@ func (ss *StringSet) Contains(s string) bool {
 	_, ok := ss.set[s]
 	return ok
 }
+
+func sortedKeys(m map[string][]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedInterfaceKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,645 @@
+package easymock
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	wireMockDefaultPriority = 5
+	wireMockHostPattern     = `^[a-zA-Z][a-zA-Z0-9+.-]*://[^/]+`
+)
+
+var wireMockAnyMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+type WireMockMapping struct {
+	ID         string           `json:"id,omitempty"`
+	UUID       string           `json:"uuid,omitempty"`
+	Name       string           `json:"name,omitempty"`
+	Priority   int              `json:"priority,omitempty"`
+	Persistent bool             `json:"persistent,omitempty"`
+	Metadata   interface{}      `json:"metadata,omitempty"`
+	Request    WireMockRequest  `json:"request"`
+	Response   WireMockResponse `json:"response"`
+
+	ScenarioName          string `json:"scenarioName,omitempty"`
+	RequiredScenarioState string `json:"requiredScenarioState,omitempty"`
+	NewScenarioState      string `json:"newScenarioState,omitempty"`
+
+	file     string
+	path     string
+	filesDir string
+}
+
+type WireMockRequest struct {
+	Method               string                          `json:"method,omitempty"`
+	URL                  string                          `json:"url,omitempty"`
+	URLPattern           string                          `json:"urlPattern,omitempty"`
+	URLPath              string                          `json:"urlPath,omitempty"`
+	URLPathPattern       string                          `json:"urlPathPattern,omitempty"`
+	QueryParameters      map[string]WireMockValuePattern `json:"queryParameters,omitempty"`
+	Headers              map[string]WireMockValuePattern `json:"headers,omitempty"`
+	Cookies              map[string]WireMockValuePattern `json:"cookies,omitempty"`
+	BasicAuthCredentials *WireMockBasicAuth              `json:"basicAuthCredentials,omitempty"`
+	BodyPatterns         []WireMockValuePattern          `json:"bodyPatterns,omitempty"`
+}
+
+type WireMockValuePattern struct {
+	EqualTo             *string     `json:"equalTo,omitempty"`
+	CaseInsensitive     bool        `json:"caseInsensitive,omitempty"`
+	Contains            *string     `json:"contains,omitempty"`
+	Matches             *string     `json:"matches,omitempty"`
+	DoesNotMatch        *string     `json:"doesNotMatch,omitempty"`
+	Absent              bool        `json:"absent,omitempty"`
+	BinaryEqualTo       *string     `json:"binaryEqualTo,omitempty"`
+	EqualToJSON         interface{} `json:"equalToJson,omitempty"`
+	IgnoreArrayOrder    bool        `json:"ignoreArrayOrder,omitempty"`
+	IgnoreExtraElements bool        `json:"ignoreExtraElements,omitempty"`
+	MatchesJSONPath     interface{} `json:"matchesJsonPath,omitempty"`
+}
+
+type WireMockBasicAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type WireMockResponse struct {
+	Status                 int                    `json:"status,omitempty"`
+	Headers                map[string]interface{} `json:"headers,omitempty"`
+	Body                   *string                `json:"body,omitempty"`
+	Base64Body             string                 `json:"base64Body,omitempty"`
+	JSONBody               interface{}            `json:"jsonBody,omitempty"`
+	BodyFileName           string                 `json:"bodyFileName,omitempty"`
+	FixedDelayMilliseconds int                    `json:"fixedDelayMilliseconds,omitempty"`
+	DelayDistribution      *WireMockDelay         `json:"delayDistribution,omitempty"`
+	Fault                  string                 `json:"fault,omitempty"`
+}
+
+type WireMockDelay struct {
+	Type  string `json:"type"`
+	Lower int    `json:"lower,omitempty"`
+	Upper int    `json:"upper,omitempty"`
+}
+
+// LoadWireMockMappings reads every json stub under dir, which is either a
+// WireMock root containing mappings and __files or the mappings directory
+// itself. Stubs using features EasyMock cannot emulate are left out and
+// reported in the returned DefinitionErrors.
+func LoadWireMockMappings(dir string) ([]WireMockMapping, error) {
+	mappingsDir := filepath.Join(dir, "mappings")
+	filesDir := filepath.Join(dir, "__files")
+	if info, err := os.Stat(mappingsDir); err != nil || !info.IsDir() {
+		mappingsDir = dir
+		filesDir = filepath.Join(filepath.Dir(dir), "__files")
+	}
+
+	files := make([]string, 0)
+	err := filepath.Walk(mappingsDir, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.EqualFold(filepath.Ext(file), ".json") {
+			files = append(files, file)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	mappings := make([]WireMockMapping, 0)
+	errs := make(DefinitionErrors, 0)
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileMappings, fileErrs := parseWireMockFile(file, filesDir, data)
+		mappings = append(mappings, fileMappings...)
+		errs = append(errs, fileErrs...)
+	}
+	return mappings, errorsOrNil(errs)
+}
+
+func parseWireMockFile(file, filesDir string, data []byte) ([]WireMockMapping, DefinitionErrors) {
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, DefinitionErrors{{File: file, Msg: strings.TrimPrefix(err.Error(), "json: ")}}
+	}
+
+	raws := []interface{}{doc}
+	paths := []string{""}
+	if m, ok := doc.(map[string]interface{}); ok {
+		if list, ok := m["mappings"].([]interface{}); ok {
+			raws = list
+			paths = make([]string, len(list))
+			for i := range list {
+				paths[i] = "mappings[" + strconv.Itoa(i) + "]"
+			}
+		}
+	}
+
+	mappings := make([]WireMockMapping, 0, len(raws))
+	errs := make(DefinitionErrors, 0)
+	for i, raw := range raws {
+		mapping := WireMockMapping{file: file, path: paths[i], filesDir: filesDir}
+		fieldErrs := unknownFields(raw, reflect.TypeOf(mapping), nil)
+		if len(fieldErrs) > 0 {
+			for _, fe := range fieldErrs {
+				errs = append(errs, mapping.error(fe.pathString(), "unsupported WireMock feature"))
+			}
+			continue
+		}
+		b, _ := json.Marshal(raw)
+		if err := json.Unmarshal(b, &mapping); err != nil {
+			errs = append(errs, mapping.error("", strings.TrimPrefix(err.Error(), "json: ")))
+			continue
+		}
+		mappings = append(mappings, mapping)
+	}
+	return mappings, errs
+}
+
+func (m *WireMockMapping) error(path, msg string) *DefinitionError {
+	if m.path != "" && path != "" {
+		path = m.path + "." + path
+	} else if m.path != "" {
+		path = m.path
+	}
+	return &DefinitionError{File: m.file, Path: path, Msg: msg}
+}
+
+func (mocker *EasyMocker) ImportWireMock(dir string) error {
+	mappings, loadErr := LoadWireMockMappings(dir)
+	errs := make(DefinitionErrors, 0)
+	if loadErr != nil {
+		defErrs, ok := loadErr.(DefinitionErrors)
+		if !ok {
+			return loadErr
+		}
+		errs = append(errs, defErrs...)
+	}
+	if err := mocker.RegisterWireMockMappings(mappings); err != nil {
+		errs = append(errs, err.(DefinitionErrors)...)
+	}
+	return errorsOrNil(errs)
+}
+
+func (mocker *EasyMocker) RegisterWireMockMappings(mappings []WireMockMapping) error {
+	errs := make(DefinitionErrors, 0)
+	for i := range mappings {
+		if err := mocker.registerWireMock(&mappings[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errorsOrNil(errs)
+}
+
+func (mocker *EasyMocker) registerWireMock(m *WireMockMapping) *DefinitionError {
+	pattern, err := m.Request.urlPattern()
+	if err != nil {
+		return m.error("request", err.Error())
+	}
+	matchers, err := m.Request.matchers()
+	if err != nil {
+		return m.error("request", err.Error())
+	}
+	responder, err := m.Response.responder(m.filesDir)
+	if err != nil {
+		return m.error("response", err.Error())
+	}
+	if len(matchers) > 0 {
+		responder.When(matchers...)
+	}
+	if m.ScenarioName != "" {
+		responder.InScenario(m.ScenarioName).WhenState(m.RequiredScenarioState).WillSetStateTo(m.NewScenarioState)
+	}
+
+	priority := m.Priority
+	if priority == 0 {
+		priority = wireMockDefaultPriority
+	}
+	regexResponder := &EasyRegexResponder{EasyResponder: responder}
+	regexResponder.SetPriority(-priority)
+
+	methods := []string{strings.ToUpper(m.Request.Method)}
+	if methods[0] == "" || methods[0] == "ANY" {
+		methods = wireMockAnyMethods
+	}
+	routes := make([]*route, 0, len(methods))
+	for _, method := range methods {
+		r, err := newRegexRoute(method, pattern, regexResponder)
+		if err != nil {
+			return m.error("request", err.Error())
+		}
+		routes = append(routes, r)
+	}
+	if _, err := mocker.addRoutes(routes); err != nil {
+		return m.error("request", err.Error())
+	}
+	return nil
+}
+
+func (req *WireMockRequest) urlPattern() (string, error) {
+	var pattern string
+	set := 0
+	if req.URL != "" {
+		pattern = wireMockHostPattern + regexp.QuoteMeta(req.URL) + `$`
+		set++
+	}
+	if req.URLPattern != "" {
+		pattern = wireMockHostPattern + `(?:` + req.URLPattern + `)$`
+		set++
+	}
+	if req.URLPath != "" {
+		pattern = wireMockHostPattern + regexp.QuoteMeta(req.URLPath) + `(?:\?.*)?$`
+		set++
+	}
+	if req.URLPathPattern != "" {
+		pattern = wireMockHostPattern + `(?:` + req.URLPathPattern + `)(?:\?.*)?$`
+		set++
+	}
+	switch set {
+	case 0:
+		return wireMockHostPattern + `.*$`, nil
+	case 1:
+		_, err := regexp.Compile(pattern)
+		return pattern, err
+	}
+	return "", errors.New("only one of url, urlPattern, urlPath or urlPathPattern may be set")
+}
+
+func (req *WireMockRequest) matchers() ([]Matcher, error) {
+	matchers := make([]Matcher, 0)
+	for _, name := range sortedWireMockKeys(req.QueryParameters) {
+		name := name
+		vp := req.QueryParameters[name]
+		m, err := vp.matcher("query "+name, func(r *http.Request) ([]string, bool) {
+			values, ok := r.URL.Query()[name]
+			return values, ok
+		})
+		if err != nil {
+			return nil, fmt.Errorf("queryParameters.%s: %v", name, err)
+		}
+		matchers = append(matchers, m)
+	}
+	for _, name := range sortedWireMockKeys(req.Headers) {
+		name := name
+		vp := req.Headers[name]
+		m, err := vp.matcher("header "+name, func(r *http.Request) ([]string, bool) {
+			values := r.Header.Values(name)
+			return values, len(values) > 0
+		})
+		if err != nil {
+			return nil, fmt.Errorf("headers.%s: %v", name, err)
+		}
+		matchers = append(matchers, m)
+	}
+	for _, name := range sortedWireMockKeys(req.Cookies) {
+		name := name
+		vp := req.Cookies[name]
+		m, err := vp.matcher("cookie "+name, func(r *http.Request) ([]string, bool) {
+			cookie, err := r.Cookie(name)
+			if err != nil {
+				return nil, false
+			}
+			return []string{cookie.Value}, true
+		})
+		if err != nil {
+			return nil, fmt.Errorf("cookies.%s: %v", name, err)
+		}
+		matchers = append(matchers, m)
+	}
+	if auth := req.BasicAuthCredentials; auth != nil {
+		matchers = append(matchers, BasicAuthEquals(auth.Username, auth.Password))
+	}
+	for i := range req.BodyPatterns {
+		m, err := req.BodyPatterns[i].bodyMatcher()
+		if err != nil {
+			return nil, fmt.Errorf("bodyPatterns[%d]: %v", i, err)
+		}
+		matchers = append(matchers, m)
+	}
+	return matchers, nil
+}
+
+func sortedWireMockKeys(m map[string]WireMockValuePattern) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (vp *WireMockValuePattern) matcher(desc string, lookup func(r *http.Request) ([]string, bool)) (Matcher, error) {
+	match, err := vp.stringMatch()
+	if err != nil {
+		return nil, err
+	}
+	if vp.Absent {
+		return NewMatcher(desc+" absent", func(r *http.Request) bool {
+			_, ok := lookup(r)
+			return !ok
+		}), nil
+	}
+	return NewMatcher(desc+" "+vp.String(), func(r *http.Request) bool {
+		values, _ := lookup(r)
+		for _, value := range values {
+			if match(value) {
+				return true
+			}
+		}
+		return false
+	}), nil
+}
+
+func (vp *WireMockValuePattern) stringMatch() (func(string) bool, error) {
+	switch {
+	case vp.EqualTo != nil:
+		expected := *vp.EqualTo
+		if vp.CaseInsensitive {
+			return func(s string) bool { return strings.EqualFold(s, expected) }, nil
+		}
+		return func(s string) bool { return s == expected }, nil
+	case vp.CaseInsensitive && vp.EqualTo == nil:
+		return nil, errors.New("caseInsensitive: unsupported WireMock feature")
+	case vp.Contains != nil:
+		substr := *vp.Contains
+		return func(s string) bool { return strings.Contains(s, substr) }, nil
+	case vp.Matches != nil:
+		re, err := regexp.Compile(`^(?:` + *vp.Matches + `)$`)
+		if err != nil {
+			return nil, err
+		}
+		return re.MatchString, nil
+	case vp.DoesNotMatch != nil:
+		re, err := regexp.Compile(`^(?:` + *vp.DoesNotMatch + `)$`)
+		if err != nil {
+			return nil, err
+		}
+		return func(s string) bool { return !re.MatchString(s) }, nil
+	case vp.Absent:
+		return nil, nil
+	}
+	return nil, errors.New("unsupported WireMock value pattern")
+}
+
+func (vp *WireMockValuePattern) String() string {
+	switch {
+	case vp.EqualTo != nil:
+		return fmt.Sprintf("== %q", *vp.EqualTo)
+	case vp.Contains != nil:
+		return fmt.Sprintf("contains %q", *vp.Contains)
+	case vp.Matches != nil:
+		return fmt.Sprintf("matches %q", *vp.Matches)
+	case vp.DoesNotMatch != nil:
+		return fmt.Sprintf("does not match %q", *vp.DoesNotMatch)
+	}
+	return "absent"
+}
+
+func (vp *WireMockValuePattern) bodyMatcher() (Matcher, error) {
+	switch {
+	case vp.EqualToJSON != nil:
+		expected, err := wireMockJSONValue(vp.EqualToJSON)
+		if err != nil {
+			return nil, fmt.Errorf("equalToJson: %v", err)
+		}
+		ignoreOrder, ignoreExtra := vp.IgnoreArrayOrder, vp.IgnoreExtraElements
+		return NewMatcher(fmt.Sprintf("json body == %v", expected), func(r *http.Request) bool {
+			body, err := peekBody(r)
+			if err != nil {
+				return false
+			}
+			var actual interface{}
+			return json.Unmarshal(body, &actual) == nil && jsonEquals(expected, actual, ignoreOrder, ignoreExtra)
+		}), nil
+	case vp.MatchesJSONPath != nil:
+		return vp.jsonPathMatcher()
+	case vp.BinaryEqualTo != nil:
+		expected, err := base64.StdEncoding.DecodeString(*vp.BinaryEqualTo)
+		if err != nil {
+			return nil, fmt.Errorf("binaryEqualTo: %v", err)
+		}
+		return NewMatcher("body == binary", func(r *http.Request) bool {
+			body, err := peekBody(r)
+			return err == nil && bytes.Equal(body, expected)
+		}), nil
+	}
+
+	match, err := vp.stringMatch()
+	if err != nil {
+		return nil, err
+	}
+	if match == nil {
+		return nil, errors.New("absent is not supported for bodies")
+	}
+	return NewMatcher("body "+vp.String(), func(r *http.Request) bool {
+		body, err := peekBody(r)
+		return err == nil && match(string(body))
+	}), nil
+}
+
+func (vp *WireMockValuePattern) jsonPathMatcher() (Matcher, error) {
+	var expression string
+	var match func(string) bool
+	switch jp := vp.MatchesJSONPath.(type) {
+	case string:
+		expression = jp
+	case map[string]interface{}:
+		expression, _ = jp["expression"].(string)
+		delete(jp, "expression")
+		b, _ := json.Marshal(jp)
+		var sub WireMockValuePattern
+		if err := json.Unmarshal(b, &sub); err != nil {
+			return nil, fmt.Errorf("matchesJsonPath: %v", err)
+		}
+		if fieldErrs := unknownFields(jp, reflect.TypeOf(sub), nil); len(fieldErrs) > 0 {
+			return nil, fmt.Errorf("matchesJsonPath.%s: unsupported WireMock feature", fieldErrs[0].pathString())
+		}
+		var err error
+		if match, err = sub.stringMatch(); err != nil {
+			return nil, fmt.Errorf("matchesJsonPath: %v", err)
+		}
+	default:
+		return nil, errors.New("matchesJsonPath must be a string or an object")
+	}
+	if expression == "" || strings.Contains(expression, "..") || strings.Contains(expression, "?(") || strings.Contains(expression, "*") {
+		return nil, fmt.Errorf("json path %q is not supported", expression)
+	}
+
+	if match == nil {
+		return JSONBodyFieldPresent(expression), nil
+	}
+	return NewMatcher("json "+expression+" matches", func(r *http.Request) bool {
+		value, ok := lookupJSONBody(r, expression)
+		if !ok {
+			return false
+		}
+		if s, ok := value.(string); ok {
+			return match(s)
+		}
+		b, _ := json.Marshal(value)
+		return match(string(b))
+	}), nil
+}
+
+func wireMockJSONValue(value interface{}) (interface{}, error) {
+	if s, ok := value.(string); ok {
+		var v interface{}
+		err := json.Unmarshal([]byte(s), &v)
+		return v, err
+	}
+	return toJSONValue(value)
+}
+
+func jsonEquals(expected, actual interface{}, ignoreOrder, ignoreExtra bool) bool {
+	switch e := expected.(type) {
+	case map[string]interface{}:
+		a, ok := actual.(map[string]interface{})
+		if !ok || (!ignoreExtra && len(a) != len(e)) {
+			return false
+		}
+		for key, ev := range e {
+			av, ok := a[key]
+			if !ok || !jsonEquals(ev, av, ignoreOrder, ignoreExtra) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		a, ok := actual.([]interface{})
+		if !ok || (!ignoreExtra && len(a) != len(e)) || len(a) < len(e) {
+			return false
+		}
+		if !ignoreOrder {
+			for i := range e {
+				if !jsonEquals(e[i], a[i], ignoreOrder, ignoreExtra) {
+					return false
+				}
+			}
+			return true
+		}
+		used := make([]bool, len(a))
+		for _, ev := range e {
+			found := false
+			for i, av := range a {
+				if !used[i] && jsonEquals(ev, av, ignoreOrder, ignoreExtra) {
+					used[i], found = true, true
+					break
+				}
+			}
+			if !found {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(expected, actual)
+}
+
+func (resp *WireMockResponse) responder(filesDir string) (*EasyResponder, error) {
+	status := resp.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	var handler RequestHandler
+	if resp.Fault != "" {
+		fault, err := wireMockFault(resp.Fault, status)
+		if err != nil {
+			return nil, err
+		}
+		handler = fault
+	} else {
+		body, err := resp.body(filesDir)
+		if err != nil {
+			return nil, err
+		}
+		httpResp := NewHttpResponseWithBytes(status, body)
+		if resp.JSONBody != nil {
+			httpResp.Header.Set("Content-Type", "application/json")
+		}
+		for _, name := range sortedInterfaceKeys(resp.Headers) {
+			switch value := resp.Headers[name].(type) {
+			case []interface{}:
+				httpResp.Header.Del(name)
+				for _, v := range value {
+					httpResp.Header.Add(name, fmt.Sprint(v))
+				}
+			default:
+				httpResp.Header.Set(name, fmt.Sprint(value))
+			}
+		}
+		handler = cloneRespHandler(httpResp)
+	}
+
+	responder := NewEasyResponderWithReqHandler(handler)
+	if resp.FixedDelayMilliseconds > 0 {
+		responder.WithDelay(time.Duration(resp.FixedDelayMilliseconds) * time.Millisecond)
+	}
+	if dist := resp.DelayDistribution; dist != nil {
+		if dist.Type != "uniform" {
+			return nil, fmt.Errorf("delayDistribution type '%s' is not supported", dist.Type)
+		}
+		responder.WithRandomDelay(time.Duration(dist.Lower)*time.Millisecond, time.Duration(dist.Upper)*time.Millisecond)
+	}
+	return responder, nil
+}
+
+func (resp *WireMockResponse) body(filesDir string) ([]byte, error) {
+	switch {
+	case resp.BodyFileName != "":
+		body, err := ioutil.ReadFile(filepath.Join(filesDir, filepath.FromSlash(resp.BodyFileName)))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read bodyFileName: %v", err)
+		}
+		return body, nil
+	case resp.Base64Body != "":
+		return base64.StdEncoding.DecodeString(resp.Base64Body)
+	case resp.JSONBody != nil:
+		return json.Marshal(resp.JSONBody)
+	case resp.Body != nil:
+		return []byte(*resp.Body), nil
+	}
+	return nil, nil
+}
+
+func wireMockFault(fault string, status int) (RequestHandler, error) {
+	switch fault {
+	case "CONNECTION_RESET_BY_PEER":
+		return func(req *http.Request) (*http.Response, error) {
+			return nil, connResetError(req)
+		}, nil
+	case "EMPTY_RESPONSE":
+		return func(req *http.Request) (*http.Response, error) {
+			return nil, io.EOF
+		}, nil
+	case "MALFORMED_RESPONSE_CHUNK":
+		return NewUnexpectedEOFEasyResponder(status, nil).reqHandler, nil
+	case "RANDOM_DATA_THEN_CLOSE":
+		return func(req *http.Request) (*http.Response, error) {
+			return nil, errors.New("net/http: HTTP/1.x transport connection broken: malformed HTTP response")
+		}, nil
+	}
+	return nil, fmt.Errorf("fault '%s' is not supported", fault)
+}
@@ -1,6 +1,7 @@
 package example
 
 import (
+	"encoding/json"
 	"fmt"
 	"github.com/SCU-SJL/easymock/easymock"
 	"github.com/stretchr/testify/suite"
@@ -9,11 +10,15 @@ import (
 	"testing"
 )
 
-const addBookUrlPrefix = `https://www.easymock.com/bookstore/add?[0-9a-z&]+`
+const (
+	addBookUrlPrefix   = `https://www.easymock.com/bookstore/add?[0-9a-z&]+`
+	bookDetailTemplate = "https://www.easymock.com/bookstore/books/{name}"
+)
 
 type BookStoreTestSuite struct {
 	suite.Suite
-	books []Book
+	mocker *easymock.EasyMocker
+	books  []Book
 }
 
 func TestBookStore(t *testing.T) {
@@ -21,9 +26,11 @@ func TestBookStore(t *testing.T) {
 }
 
 func (bs *BookStoreTestSuite) SetupSuite() {
-	easymock.Start()
-	easymock.RegisterResponder(http.MethodGet, listBooksUrl, bs.MockListAllBooks())
-	easymock.RegisterRegexResponder(http.MethodPost, addBookUrlPrefix, bs.MockAddBook())
+	bs.mocker = easymock.NewEasyMockerTransport()
+	bs.mocker.Start()
+	bs.mocker.RegisterResponder(http.MethodGet, listBooksUrl, bs.MockListAllBooks())
+	bs.mocker.RegisterRegexResponder(http.MethodPost, addBookUrlPrefix, bs.MockAddBook())
+	bs.mocker.RegisterTemplateResponder(http.MethodGet, bookDetailTemplate, bs.MockBookDetail())
 }
 
 func (bs *BookStoreTestSuite) BeforeTest(suiteName, testName string) {
@@ -35,7 +42,7 @@ func (bs *BookStoreTestSuite) AfterTest(suiteName, testName string) {
 }
 
 func (bs *BookStoreTestSuite) TearDownSuite() {
-	easymock.Shutdown()
+	bs.mocker.Shutdown()
 }
 
 func (bs *BookStoreTestSuite) MockListAllBooks() *easymock.EasyResponder {
@@ -67,6 +74,24 @@ func (bs *BookStoreTestSuite) MockAddBook() *easymock.EasyRegexResponder {
 	return regexResponder
 }
 
+// MockBookDetail answers with the book named in the path.
+func (bs *BookStoreTestSuite) MockBookDetail() *easymock.EasyResponder {
+	responder, err := easymock.NewTemplateEasyResponder(http.StatusOK,
+		`{"name": {{json (.PathParam "name")}}, "author": {{json (.Query "author" | default "unknown")}}}`,
+		map[string]string{"Content-Type": "application/json", "X-Request-Id": "{{.RequestID}}"})
+	bs.Require().Nil(err)
+	return responder
+}
+
+func (bs *BookStoreTestSuite) TestBookDetail() {
+	resp, err := http.Get("https://www.easymock.com/bookstore/books/Hello?author=sjl")
+	bs.Nil(err)
+	var book Book
+	bs.Nil(json.NewDecoder(resp.Body).Decode(&book))
+	bs.Equal(Book{Name: "Hello", Author: "sjl"}, book)
+	bs.NotEmpty(resp.Header.Get("X-Request-Id"))
+}
+
 func (bs *BookStoreTestSuite) TestAddBook() {
 	req, err := http.NewRequest(http.MethodPost, "https://www.easymock.com/bookstore/add?name=Hello&price=34.2&author=sjl", nil)
 	bs.Nil(err)
@@ -0,0 +1,132 @@
+package test
+
+import (
+	"encoding/json"
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func adminCall(t *testing.T, client *http.Client, method, url, body string, out interface{}) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	assert.Nil(t, err)
+	resp, err := client.Do(req)
+	if !assert.Nil(t, err) {
+		return 0
+	}
+	defer resp.Body.Close()
+	if out != nil {
+		assert.Nil(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+	return resp.StatusCode
+}
+
+func TestAdminRoutes(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, "https://api.easymock.com/health", easymock.NewStringEasyResponder(http.StatusOK, "ok"))
+	handler, err := mocker.Handler("https://api.easymock.com")
+	assert.Nil(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+	client := server.Client()
+	admin := server.URL + easymock.AdminPrefix
+	mocker.EnableAdmin()
+
+	var created easymock.RouteInfo
+	status := adminCall(t, client, http.MethodPost, admin+"routes",
+		`{"id": "books", "method": "GET", "url": "https://api.easymock.com/books", "response": {"status": 200, "body": "books"}}`, &created)
+	assert.Equal(t, http.StatusCreated, status)
+	assert.Equal(t, "books", created.ID)
+	assert.Equal(t, "exact", created.Kind)
+
+	var invalid map[string][]string
+	status = adminCall(t, client, http.MethodPost, admin+"routes", `{"method": "GET", "response": {"status": 200}}`, &invalid)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, []string{"exactly one of url, template or pattern must be set"}, invalid["errors"])
+
+	resp, err := client.Get(server.URL + "/books")
+	assert.Equal(t, "books", readBody(t, resp, err))
+
+	var routes []easymock.RouteInfo
+	assert.Equal(t, http.StatusOK, adminCall(t, client, http.MethodGet, admin+"routes", "", &routes))
+	if assert.Len(t, routes, 2) {
+		assert.Equal(t, "1", routes[0].ID)
+		assert.Equal(t, 1, routes[1].Calls)
+	}
+
+	var disabled easymock.RouteInfo
+	assert.Equal(t, http.StatusOK, adminCall(t, client, http.MethodPost, admin+"routes/books/disable", "", &disabled))
+	assert.False(t, disabled.Enabled)
+	resp, err = client.Get(server.URL + "/books")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+	assert.Equal(t, http.StatusOK, adminCall(t, client, http.MethodPost, admin+"routes/books/enable", "", nil))
+
+	var counts easymock.CallCounts
+	assert.Equal(t, http.StatusOK, adminCall(t, client, http.MethodGet, admin+"counts", "", &counts))
+	assert.Equal(t, 2, counts.Total)
+	assert.Equal(t, 1, counts.Unmatched)
+	assert.Equal(t, 1, counts.Routes["GET https://api.easymock.com/books"])
+
+	var journal []easymock.JournalRecord
+	assert.Equal(t, http.StatusOK, adminCall(t, client, http.MethodGet, admin+"journal?unmatched=true", "", &journal))
+	if assert.Len(t, journal, 1) {
+		assert.Equal(t, "https://api.easymock.com/books", journal[0].URL)
+		assert.Contains(t, journal[0].Err, "routing failed")
+	}
+
+	assert.Equal(t, http.StatusNoContent, adminCall(t, client, http.MethodDelete, admin+"routes/books", "", nil))
+	assert.Equal(t, http.StatusNotFound, adminCall(t, client, http.MethodDelete, admin+"routes/books", "", nil))
+	assert.Equal(t, []string{"GET https://api.easymock.com/health"}, mocker.Routes())
+
+	assert.Equal(t, http.StatusNoContent, adminCall(t, client, http.MethodPost, admin+"reset", "", nil))
+	assert.Empty(t, mocker.Routes())
+	assert.Equal(t, 0, mocker.TotalCallCount())
+}
+
+func TestAdminIsOptInAndRejectsBodyFiles(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	server := mocker.NewServer()
+	defer server.Close()
+	client := server.Client()
+	admin := server.URL + easymock.AdminPrefix
+	route := `{"method": "GET", "url": "http://api.easymock.com/secret", "response": {"status": 200, "body_file": "/etc/hostname"}}`
+
+	assert.Equal(t, http.StatusBadGateway, adminCall(t, client, http.MethodPost, admin+"routes", route, nil))
+	assert.Empty(t, mocker.Routes())
+
+	mocker.EnableAdmin()
+	var rejected map[string][]string
+	assert.Equal(t, http.StatusBadRequest, adminCall(t, client, http.MethodPost, admin+"routes", route, &rejected))
+	if assert.Len(t, rejected["errors"], 1) {
+		assert.Contains(t, rejected["errors"][0], "response.body_file")
+	}
+	assert.Empty(t, mocker.Routes())
+
+	mocker.DisableAdmin()
+	assert.Equal(t, http.StatusBadGateway, adminCall(t, client, http.MethodGet, admin+"routes", "", nil))
+}
+
+func TestAdminCreateRoutesIsAtomic(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.EnableAdmin()
+	server := mocker.NewServer()
+	defer server.Close()
+	client := server.Client()
+	admin := server.URL + easymock.AdminPrefix
+	assert.Equal(t, http.StatusCreated, adminCall(t, client, http.MethodPost, admin+"routes",
+		`{"id": "books", "method": "GET", "url": "https://api.easymock.com/books", "response": {"status": 200}}`, nil))
+
+	var conflict map[string][]string
+	status := adminCall(t, client, http.MethodPost, admin+"routes", `[
+		{"id": "orders", "method": "GET", "url": "https://api.easymock.com/orders", "response": {"status": 200}},
+		{"id": "books", "method": "GET", "url": "https://api.easymock.com/shelves", "response": {"status": 200}}
+	]`, &conflict)
+	assert.Equal(t, http.StatusConflict, status)
+	assert.Len(t, conflict["errors"], 1)
+	assert.Equal(t, []string{"GET https://api.easymock.com/books"}, mocker.Routes())
+}
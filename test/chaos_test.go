@@ -0,0 +1,67 @@
+package test
+
+import (
+	"errors"
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func chaosStatuses(t *testing.T, cfg easymock.ChaosConfig, n int) []int {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, mockGoogleStrResp))
+	mocker.EnableChaos(cfg)
+	return collectStatuses(t, &http.Client{Transport: mocker}, n)
+}
+
+func TestChaosIsReproducible(t *testing.T) {
+	cfg := easymock.ChaosConfig{FailureRate: 0.5, StatusCode: http.StatusBadGateway, Seed: 42}
+	first := chaosStatuses(t, cfg, 50)
+	second := chaosStatuses(t, cfg, 50)
+	assert.Equal(t, first, second)
+	assert.Contains(t, first, http.StatusOK)
+	assert.Contains(t, first, http.StatusBadGateway)
+
+	assert.NotContains(t, chaosStatuses(t, easymock.ChaosConfig{Seed: 1}, 20), http.StatusServiceUnavailable)
+	assert.NotContains(t, chaosStatuses(t, easymock.ChaosConfig{FailureRate: 1, Seed: 1}, 20), http.StatusOK)
+}
+
+func TestChaosOnRouteWithErrorAndLatency(t *testing.T) {
+	chaosErr := errors.New("chaos")
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, "").
+		WithChaos(easymock.ChaosConfig{FailureRate: 1, Err: chaosErr, Seed: 7}))
+	mocker.RegisterResponder(http.MethodGet, mockNoResponseUrl, easymock.NewStringEasyResponder(http.StatusOK, "").
+		WithChaos(easymock.ChaosConfig{LatencyRate: 1, Latency: 20 * time.Millisecond, Seed: 7}))
+	cli := &http.Client{Transport: mocker}
+
+	_, err := cli.Get(mockGoogleUrl)
+	assert.True(t, errors.Is(err, chaosErr))
+
+	start := time.Now()
+	resp, err := cli.Get(mockNoResponseUrl)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+
+	mocker.DisableChaos()
+	assert.Equal(t, 2, mocker.TotalCallCount())
+}
+
+func TestChaosSeeds(t *testing.T) {
+	cfg := easymock.ChaosConfig{FailureRate: 0.5, StatusCode: http.StatusBadGateway}
+	assert.Equal(t, chaosStatuses(t, cfg, 50), chaosStatuses(t, cfg, 50))
+
+	mocker := easymock.NewEasyMockerTransport()
+	_, ok := mocker.ChaosSeed()
+	assert.False(t, ok)
+	mocker.EnableChaos(easymock.ChaosConfig{FailureRate: 0.5, RandomSeed: true})
+	seed, ok := mocker.ChaosSeed()
+	assert.True(t, ok)
+	assert.NotZero(t, seed)
+
+	cfg.Seed = seed
+	assert.Equal(t, chaosStatuses(t, cfg, 50), chaosStatuses(t, cfg, 50))
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/client"
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"os"
+	"testing"
+)
+
+func TestClientDrivesRemoteServer(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.EnableAdmin()
+	server := mocker.NewServer()
+	defer server.Close()
+	c := client.New(server.URL)
+
+	dir := writeDefinitionFiles(t, map[string]string{
+		"orders.yaml": mockDefinitionYAML,
+		"token.txt":   "secret",
+	})
+	defer os.RemoveAll(dir)
+	infos, err := c.LoadDefinitions(dir)
+	assert.Nil(t, err)
+	assert.Len(t, infos, 2)
+
+	health, err := c.Register(easymock.RouteDefinition{
+		ID:       "health",
+		Method:   http.MethodGet,
+		URL:      "http://api.easymock.com/health",
+		Response: easymock.ResponseDefinition{Status: http.StatusOK, Body: "ok"},
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "health", health.ID)
+	c.ExpectCalls("health", easymock.ExactlyOnce())
+
+	_, err = c.Register(easymock.RouteDefinition{
+		ID:       "health",
+		Method:   http.MethodGet,
+		URL:      "http://api.easymock.com/status",
+		Response: easymock.ResponseDefinition{Status: http.StatusOK, Body: "ok"},
+	})
+	apiErr, ok := err.(*client.APIError)
+	if assert.True(t, ok) {
+		assert.Equal(t, http.StatusConflict, apiErr.StatusCode)
+		assert.Equal(t, []string{"route with id 'health' already exists"}, apiErr.Messages)
+		assert.Contains(t, apiErr.Error(), "returned 409")
+	}
+
+	unmet, err := c.UnmetExpectations()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"GET http://api.easymock.com/health (health): expected exactly 1 call(s), got 0"}, unmet)
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/health", nil)
+	req.Host = "api.easymock.com"
+	resp, err := server.Client().Do(req)
+	assert.Equal(t, "ok", readBody(t, resp, err))
+	assert.True(t, c.AssertExpectations(t))
+
+	journal, err := c.Journal()
+	assert.Nil(t, err)
+	if assert.Len(t, journal, 1) {
+		assert.Equal(t, "http://api.easymock.com/health", journal[0].URL)
+		assert.Equal(t, "ok", journal[0].RespBody)
+	}
+
+	assert.Nil(t, c.Disable("health"))
+	route, err := c.Route("health")
+	assert.Nil(t, err)
+	assert.False(t, route.Enabled)
+	assert.Nil(t, c.Remove("health"))
+	_, err = c.Route("health")
+	assert.NotNil(t, err)
+
+	assert.Nil(t, c.Reset())
+	routes, err := c.Routes()
+	assert.Nil(t, err)
+	assert.Empty(t, routes)
+}
@@ -0,0 +1,215 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mockDefinitionYAML = `routes:
+  - method: GET
+    template: https://api.easymock.com/orders/{id:[0-9]+}
+    response:
+      status: 200
+      json_body:
+        id: 1
+        items: [apple, pear]
+  - method: POST
+    url: https://api.easymock.com/login
+    matchers:
+      - type: json_equals
+        path: user
+        value: sjl
+    times: 1
+    response:
+      status: 201
+      headers:
+        X-Token: abc
+      body_file: token.txt
+`
+
+const mockDefinitionJSON = `{
+  "routes": [
+    {
+      "method": "GET",
+      "pattern": "^https://api\\.easymock\\.com/books/.*",
+      "priority": 2,
+      "response": {"status": 200, "body": "book"}
+    }
+  ]
+}`
+
+func writeDefinitionFiles(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "easymock")
+	assert.Nil(t, err)
+	for name, content := range files {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	return dir
+}
+
+func TestLoadDefinitionDir(t *testing.T) {
+	dir := writeDefinitionFiles(t, map[string]string{
+		"orders.yaml": mockDefinitionYAML,
+		"books.json":  mockDefinitionJSON,
+		"token.txt":   "secret",
+	})
+	defer os.RemoveAll(dir)
+
+	mocker := easymock.NewEasyMockerTransport()
+	assert.Nil(t, mocker.LoadDefinitions(dir))
+	client := &http.Client{Transport: mocker}
+
+	resp, err := client.Get("https://api.easymock.com/orders/7")
+	assert.Equal(t, `{"id":1,"items":["apple","pear"]}`, readBody(t, resp, err))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	resp, err = client.Get("https://api.easymock.com/books/go")
+	assert.Equal(t, "book", readBody(t, resp, err))
+
+	resp, err = client.Post("https://api.easymock.com/login", "application/json", strings.NewReader(`{"user":"sjl"}`))
+	assert.Equal(t, "secret", readBody(t, resp, err))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "abc", resp.Header.Get("X-Token"))
+
+	_, err = client.Post("https://api.easymock.com/login", "application/json", strings.NewReader(`{"user":"sjl"}`))
+	assert.NotNil(t, err)
+}
+
+func TestDefinitionErrors(t *testing.T) {
+	yamlDefs := `routes:
+  - method: GET
+    url: https://api.easymock.com/a
+    response:
+      status: 200
+  - method: GET
+    url: https://api.easymock.com/b
+    matchers:
+      - type: header_equal
+        name: X-Id
+    response:
+      status: 200
+  - method: GET
+    url: https://api.easymock.com/c
+    response:
+      stauts: 200
+`
+	defs, err := easymock.ParseDefinitions("mocks.yaml", []byte(yamlDefs))
+	assert.Len(t, defs, 1)
+	errs, ok := err.(easymock.DefinitionErrors)
+	assert.True(t, ok)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, "mocks.yaml:9: routes[1].matchers[0].type: unknown matcher type 'header_equal'", errs[0].Error())
+	assert.Equal(t, "mocks.yaml:16: routes[2].response.stauts: unknown field", errs[1].Error())
+
+	jsonDefs := `{"routes": [
+  {"method": "GET", "url": "https://api.easymock.com/a", "response": {"status": 200}},
+  {"method": "GET", "template": "https://api.easymock.com/{id", "response": {"status": 700}}
+]}`
+	defs, err = easymock.ParseDefinitions("mocks.json", []byte(jsonDefs))
+	assert.Len(t, defs, 1)
+	errs = err.(easymock.DefinitionErrors)
+	assert.Len(t, errs, 2)
+	assert.Equal(t, 3, errs[0].Line)
+	assert.Equal(t, "routes[1].template", errs[0].Path)
+	assert.Equal(t, "routes[1].response.status", errs[1].Path)
+
+	_, err = easymock.ParseDefinitions("broken.json", []byte("{\n  \"routes\": [\n    {\"method\": }\n  ]\n}"))
+	assert.Equal(t, 3, err.(easymock.DefinitionErrors)[0].Line)
+	jsonDefs = `{"routes": [
+  {"method": "GET", "url": "https://api.easymock.com/a", "response": {"status": 200}}
+],
+"rotues": []}`
+	defs, err = easymock.ParseDefinitions("mocks.json", []byte(jsonDefs))
+	assert.Len(t, defs, 1)
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "mocks.json:4: rotues: unknown field", err.Error())
+	}
+}
+
+func TestRegisterDefinition(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	def := easymock.RouteDefinition{
+		Method:   http.MethodGet,
+		URL:      "https://api.easymock.com/search?q=go",
+		Query:    "subset",
+		Response: easymock.ResponseDefinition{Status: http.StatusOK, Body: "found"},
+	}
+	assert.Nil(t, mocker.RegisterDefinition(def))
+	assert.NotNil(t, mocker.RegisterDefinition(def))
+
+	client := &http.Client{Transport: mocker}
+	resp, err := client.Get("https://api.easymock.com/search?q=go&page=2")
+	assert.Equal(t, "found", readBody(t, resp, err))
+
+	def.Query = "fuzzy"
+	assert.NotNil(t, def.Validate())
+}
+
+func TestRegisterDefinitionsIsAtomic(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	books := easymock.RouteDefinition{
+		Method:   http.MethodGet,
+		URL:      "https://api.easymock.com/books",
+		Response: easymock.ResponseDefinition{Status: http.StatusOK},
+	}
+	assert.Nil(t, mocker.RegisterDefinition(books))
+
+	orders := books
+	orders.URL = "https://api.easymock.com/orders"
+	err := mocker.RegisterDefinitions([]easymock.RouteDefinition{orders, books})
+	if assert.NotNil(t, err) {
+		assert.Len(t, err.(easymock.DefinitionErrors), 1)
+	}
+	assert.Equal(t, []string{"GET https://api.easymock.com/books"}, mocker.Routes())
+}
+
+func TestReloadDefinitionsKeepsOtherState(t *testing.T) {
+	dir := writeDefinitionFiles(t, map[string]string{"books.json": mockDefinitionJSON})
+	defer os.RemoveAll(dir)
+	mocker := easymock.NewEasyMockerTransport()
+	defs, err := easymock.LoadDefinitions(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, mocker.ReloadDefinitions(defs))
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, mockGoogleStrResp))
+	assert.Nil(t, mocker.RegisterDefinition(easymock.RouteDefinition{
+		ID:       "remote",
+		Method:   http.MethodGet,
+		URL:      "https://api.easymock.com/remote",
+		Response: easymock.ResponseDefinition{Status: http.StatusOK, Body: "remote"},
+	}))
+	mocker.SetScenarioState("cart", "HAS_ITEMS")
+	cli := &http.Client{Transport: mocker}
+	resp, err := cli.Get("https://api.easymock.com/books/1")
+	assert.Equal(t, "book", readBody(t, resp, err))
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "books.json"),
+		[]byte(strings.Replace(mockDefinitionJSON, `"body": "book"`, `"body": "new book"`, 1)), 0644))
+	defs, err = easymock.LoadDefinitions(dir)
+	assert.Nil(t, err)
+	assert.Nil(t, mocker.ReloadDefinitions(defs))
+
+	resp, err = cli.Get("https://api.easymock.com/books/1")
+	assert.Equal(t, "new book", readBody(t, resp, err))
+	resp, err = cli.Get("https://api.easymock.com/remote")
+	assert.Equal(t, "remote", readBody(t, resp, err))
+	assert.Len(t, mocker.RouteInfos(), 3)
+	assert.Len(t, mocker.Journal(), 3)
+	assert.Equal(t, 2, mocker.RegexCallCount(http.MethodGet, `^https://api\.easymock\.com/books/.*`))
+	assert.Equal(t, "HAS_ITEMS", mocker.ScenarioState("cart"))
+
+	conflicting := []easymock.RouteDefinition{{
+		Method:   http.MethodGet,
+		URL:      mockGoogleUrl,
+		Response: easymock.ResponseDefinition{Status: http.StatusOK},
+	}}
+	assert.NotNil(t, mocker.ReloadDefinitions(conflicting))
+	resp, err = cli.Get("https://api.easymock.com/books/1")
+	assert.Equal(t, "new book", readBody(t, resp, err))
+	assert.Len(t, mocker.RouteInfos(), 3)
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"context"
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDelayedResponder(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl,
+		easymock.NewStringEasyResponder(http.StatusOK, mockGoogleStrResp).WithDelay(30*time.Millisecond))
+	cli := &http.Client{Transport: mocker}
+
+	start := time.Now()
+	resp, err := cli.Get(mockGoogleUrl)
+	assert.Equal(t, mockGoogleStrResp, readBody(t, resp, err))
+	assert.True(t, time.Since(start) >= 30*time.Millisecond)
+}
+
+func TestDelayedResponderHonorsContext(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl,
+		easymock.NewStringEasyResponder(http.StatusOK, "").WithRandomDelay(time.Second, 2*time.Second))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest(http.MethodGet, mockGoogleUrl, nil)
+	start := time.Now()
+	_, err := mocker.RoundTrip(req.WithContext(ctx))
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.True(t, time.Since(start) < time.Second)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = mocker.RoundTrip(req.WithContext(ctx))
+	assert.Equal(t, context.Canceled, err)
+
+	cli := &http.Client{Transport: mocker, Timeout: 20 * time.Millisecond}
+	_, err = cli.Get(mockGoogleUrl)
+	assert.NotNil(t, err)
+}
+
+func TestDelayDistributions(t *testing.T) {
+	uniform := easymock.UniformDelay(10*time.Millisecond, 20*time.Millisecond)
+	normal := easymock.NormalDelay(10*time.Millisecond, 50*time.Millisecond)
+	for i := 0; i < 100; i++ {
+		d := uniform()
+		assert.True(t, d >= 10*time.Millisecond && d < 20*time.Millisecond)
+		assert.True(t, normal() >= 0)
+	}
+	assert.Equal(t, 5*time.Millisecond, easymock.FixedDelay(5*time.Millisecond)())
+}
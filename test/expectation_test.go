@@ -0,0 +1,68 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+const mockBookUrlPattern = `^https://www\.easymock\.com/books/[0-9]+$`
+
+func TestCallCounts(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, mockGoogleStrResp))
+	mocker.RegisterRegexResponder(http.MethodGet, mockBookUrlPattern,
+		easymock.NewEasyRegexResponderWithReqHandler(func(req *http.Request) (*http.Response, error) {
+			return easymock.NewHttpResponseWithString(http.StatusOK, req.URL.Path), nil
+		}))
+	cli := &http.Client{Transport: mocker}
+
+	for i := 0; i < 2; i++ {
+		_, err := cli.Get(mockGoogleUrl)
+		assert.Nil(t, err)
+	}
+	_, err := cli.Get("https://www.easymock.com/books/1")
+	assert.Nil(t, err)
+	_, err = cli.Get(mockNoResponseUrl)
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 2, mocker.CallCount(http.MethodGet, mockGoogleUrl))
+	assert.Equal(t, 1, mocker.RegexCallCount(http.MethodGet, mockBookUrlPattern))
+	assert.Equal(t, 4, mocker.TotalCallCount())
+	assert.Equal(t, 1, mocker.UnmatchedCallCount())
+	assert.Equal(t, map[string]int{"GET " + mockNoResponseUrl: 1}, mocker.UnmatchedCalls())
+
+	mocker.Reset()
+	assert.Equal(t, 0, mocker.CallCount(http.MethodGet, mockGoogleUrl))
+	assert.Equal(t, 0, mocker.TotalCallCount())
+}
+
+func TestAssertExpectations(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, mockGoogleStrResp))
+	mocker.ExpectCalls(http.MethodGet, mockGoogleUrl, easymock.Exactly(2))
+	mocker.ExpectCalls(http.MethodPost, mockGoogleUrl, easymock.Never())
+	mocker.ExpectRegexCalls(http.MethodGet, mockBookUrlPattern, easymock.AtLeastOnce())
+	cli := &http.Client{Transport: mocker}
+
+	_, err := cli.Get(mockGoogleUrl)
+	assert.Nil(t, err)
+
+	tb := &recordingTB{TB: t}
+	assert.False(t, mocker.AssertExpectations(tb))
+	assert.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "2 expectation(s) not met")
+	assert.Contains(t, tb.errors[0], "GET "+mockGoogleUrl+": expected exactly 2 call(s), got 1")
+	assert.Contains(t, tb.errors[0], "GET "+mockBookUrlPattern+" (regex): expected at least 1 call(s), got 0")
+
+	_, err = cli.Get(mockGoogleUrl)
+	assert.Nil(t, err)
+	mocker.RegisterRegexResponder(http.MethodGet, mockBookUrlPattern, easymock.NewEasyRegexResponderWithReqHandler(
+		func(req *http.Request) (*http.Response, error) {
+			return easymock.NewHttpResponseWithString(http.StatusOK, ""), nil
+		}))
+	_, err = cli.Get("https://www.easymock.com/books/7")
+	assert.Nil(t, err)
+	assert.True(t, mocker.AssertExpectations(t))
+}
@@ -0,0 +1,101 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func newLocalServer(t *testing.T, body string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestPassthroughUnmatched(t *testing.T) {
+	server := newLocalServer(t, "real server")
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, mockGoogleStrResp))
+	mocker.PassthroughUnmatched()
+	cli := &http.Client{Transport: mocker}
+
+	resp, err := cli.Get(mockGoogleUrl)
+	assert.Equal(t, mockGoogleStrResp, readBody(t, resp, err))
+	resp, err = cli.Get(server.URL + "/anything")
+	assert.Equal(t, "real server", readBody(t, resp, err))
+
+	assert.Equal(t, 1, mocker.UnmatchedCallCount())
+	unmatched := mocker.UnmatchedRequests()
+	assert.Len(t, unmatched, 1)
+	assert.Equal(t, "(passthrough)", unmatched[0].Route)
+	assert.Equal(t, http.StatusOK, unmatched[0].StatusCode)
+}
+
+func TestPassthroughUnmatchedUsesClientTransport(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("tls server"))
+	}))
+	defer server.Close()
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.PassthroughUnmatched()
+	cli := server.Client()
+	mocker.StartWithClient(cli)
+	defer mocker.Shutdown()
+
+	resp, err := cli.Get(server.URL + "/anything")
+	assert.Equal(t, "tls server", readBody(t, resp, err))
+	assert.Equal(t, 1, mocker.UnmatchedCallCount())
+}
+
+func TestPassthroughUnmatchedHosts(t *testing.T) {
+	server := newLocalServer(t, "local")
+	serverUrl, err := url.Parse(server.URL)
+	assert.Nil(t, err)
+
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.PassthroughUnmatchedHosts(serverUrl.Host)
+	cli := &http.Client{Transport: mocker}
+
+	resp, err := cli.Get(server.URL)
+	assert.Equal(t, "local", readBody(t, resp, err))
+	_, err = cli.Get(mockNoResponseUrl)
+	assert.NotNil(t, err)
+
+	mocker.PassthroughUnmatchedHosts(serverUrl.Hostname())
+	resp, err = cli.Get(server.URL)
+	assert.Equal(t, "local", readBody(t, resp, err))
+
+	mocker.FailUnmatched()
+	_, err = cli.Get(server.URL)
+	assert.NotNil(t, err)
+}
+
+func TestRespondUnmatched(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RespondUnmatched(easymock.NewStringEasyResponder(http.StatusNotFound, "nothing here"))
+	cli := &http.Client{Transport: mocker}
+
+	for i := 0; i < 2; i++ {
+		resp, err := cli.Get(mockNoResponseUrl)
+		assert.Equal(t, "nothing here", readBody(t, resp, err))
+		assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	}
+	assert.Equal(t, 2, mocker.UnmatchedCallCount())
+}
+
+func TestRespondUnmatchedWithNilResponderFails(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RespondUnmatched(easymock.NewStringEasyResponder(http.StatusNotFound, "nothing here"))
+	mocker.RespondUnmatched(nil)
+	cli := &http.Client{Transport: mocker}
+
+	assert.NotPanics(t, func() {
+		_, err := cli.Get(mockNoResponseUrl)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,86 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestConnectionFaults(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	cli := &http.Client{Transport: mocker}
+
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewConnRefusedEasyResponder())
+	_, err := cli.Get(mockGoogleUrl)
+	urlErr, ok := err.(*url.Error)
+	assert.True(t, ok)
+	opErr, ok := urlErr.Err.(*net.OpError)
+	assert.True(t, ok)
+	assert.Equal(t, "dial", opErr.Op)
+	assert.True(t, errors.Is(err, syscall.ECONNREFUSED))
+	assert.False(t, urlErr.Timeout())
+
+	mocker.Reset()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewDNSErrorEasyResponder())
+	_, err = cli.Get(mockGoogleUrl)
+	var dnsErr *net.DNSError
+	assert.True(t, errors.As(err, &dnsErr))
+	assert.Equal(t, "www.google.com", dnsErr.Name)
+	assert.True(t, dnsErr.IsNotFound)
+
+	mocker.Reset()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewTimeoutEasyResponder())
+	_, err = cli.Get(mockGoogleUrl)
+	var netErr net.Error
+	assert.True(t, errors.As(err, &netErr))
+	assert.True(t, netErr.Timeout())
+
+	mocker.Reset()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewTLSHandshakeErrorEasyResponder())
+	_, err = cli.Get(mockGoogleUrl)
+	assert.Contains(t, err.Error(), "remote error: tls: handshake failure")
+}
+
+func TestBodyFaults(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	cli := &http.Client{Transport: mocker}
+
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewConnResetEasyResponder(http.StatusOK, []byte("partial")))
+	resp, err := cli.Get(mockGoogleUrl)
+	assert.Nil(t, err)
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.Equal(t, "partial", string(body))
+	assert.True(t, errors.Is(err, syscall.ECONNRESET))
+
+	mocker.Reset()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewUnexpectedEOFEasyResponder(http.StatusOK, []byte("half")))
+	resp, err = cli.Get(mockGoogleUrl)
+	assert.Nil(t, err)
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestHangingBodyFault(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewHangingBodyEasyResponder(http.StatusOK))
+	cli := &http.Client{Transport: mocker}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	req, _ := http.NewRequest(http.MethodGet, mockGoogleUrl, nil)
+	resp, err := cli.Do(req.WithContext(ctx))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
@@ -0,0 +1,111 @@
+package test
+
+import (
+	"encoding/base64"
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mockHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "browser", "version": "1"},
+    "entries": [
+      {
+        "startedDateTime": "2020-10-01T10:00:00.000Z",
+        "time": 12,
+        "request": {"method": "GET", "url": "https://api.easymock.com/profile?id=1", "httpVersion": "HTTP/2", "headers": [], "queryString": [], "cookies": [], "headersSize": -1, "bodySize": 0},
+        "response": {
+          "status": 200, "statusText": "OK", "httpVersion": "HTTP/2",
+          "headers": [{"name": ":status", "value": "200"}, {"name": "X-Request-Id", "value": "abc"}],
+          "cookies": [],
+          "content": {"size": 13, "mimeType": "application/json", "text": "{\"name\":\"sjl\"}"},
+          "redirectURL": "", "headersSize": -1, "bodySize": 13
+        },
+        "cache": {},
+        "timings": {"send": 0, "wait": 12, "receive": 0}
+      },
+      {
+        "startedDateTime": "2020-10-01T10:00:01.000Z",
+        "time": 5,
+        "request": {"method": "GET", "url": "https://api.easymock.com/logo.png", "httpVersion": "HTTP/2", "headers": [], "queryString": [], "cookies": [], "headersSize": -1, "bodySize": 0},
+        "response": {
+          "status": 200, "statusText": "OK", "httpVersion": "HTTP/2", "headers": [], "cookies": [],
+          "content": {"size": 4, "mimeType": "image/png", "text": "` + "iVBORw==" + `", "encoding": "base64"},
+          "redirectURL": "", "headersSize": -1, "bodySize": 4
+        },
+        "cache": {},
+        "timings": {"send": 0, "wait": 5, "receive": 0}
+      }
+    ]
+  }
+}`
+
+func TestRegisterHAR(t *testing.T) {
+	dir, err := ioutil.TempDir("", "easymock")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "capture.har")
+	assert.Nil(t, ioutil.WriteFile(path, []byte(mockHAR), 0644))
+
+	har, err := easymock.LoadHAR(path)
+	assert.Nil(t, err)
+	mocker := easymock.NewEasyMockerTransport()
+	assert.Nil(t, mocker.RegisterHAR(har))
+	cli := &http.Client{Transport: mocker}
+
+	resp, err := cli.Get("https://api.easymock.com/profile?id=1")
+	assert.Equal(t, `{"name":"sjl"}`, readBody(t, resp, err))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+	assert.Equal(t, "abc", resp.Header.Get("X-Request-Id"))
+
+	resp, err = cli.Get("https://api.easymock.com/logo.png")
+	png, _ := base64.StdEncoding.DecodeString("iVBORw==")
+	assert.Equal(t, string(png), readBody(t, resp, err))
+}
+
+func TestExportHAR(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodPost, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusCreated, "created"))
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewBytesEasyResponder(http.StatusOK, []byte{0xff, 0x00}))
+	cli := &http.Client{Transport: mocker}
+
+	_, err := cli.Post(mockGoogleUrl+"?a=1", "text/plain", strings.NewReader("hello"))
+	assert.NotNil(t, err)
+	_, err = cli.Post(mockGoogleUrl, "text/plain", strings.NewReader("hello"))
+	assert.Nil(t, err)
+	_, err = cli.Get(mockGoogleUrl)
+	assert.Nil(t, err)
+
+	har := mocker.ExportHAR()
+	assert.Equal(t, "1.2", har.Log.Version)
+	assert.Len(t, har.Log.Entries, 3)
+
+	unmatched := har.Log.Entries[0]
+	assert.Equal(t, http.MethodPost, unmatched.Request.Method)
+	assert.Equal(t, []easymock.HARNameValue{{Name: "a", Value: "1"}}, unmatched.Request.QueryString)
+
+	created := har.Log.Entries[1]
+	assert.Equal(t, "hello", created.Request.PostData.Text)
+	assert.Equal(t, http.StatusCreated, created.Response.Status)
+	assert.Equal(t, "created", created.Response.Content.Text)
+
+	binary := har.Log.Entries[2]
+	assert.Equal(t, "base64", binary.Response.Content.Encoding)
+	assert.Equal(t, base64.StdEncoding.EncodeToString([]byte{0xff, 0x00}), binary.Response.Content.Text)
+
+	dir, err := ioutil.TempDir("", "easymock")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "export.har")
+	assert.Nil(t, har.Save(path))
+	loaded, err := easymock.LoadHAR(path)
+	assert.Nil(t, err)
+	assert.Len(t, loaded.Log.Entries, 3)
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func TestInstanceMockersInParallel(t *testing.T) {
+	cases := []struct {
+		name string
+		body string
+	}{
+		{name: "first", body: "first mocker"},
+		{name: "second", body: "second mocker"},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			mocker := easymock.NewEasyMockerTransport()
+			mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, c.body))
+
+			cli := &http.Client{Transport: mocker}
+			resp, err := cli.Get(mockGoogleUrl)
+			assert.Nil(t, err)
+			body, err := ioutil.ReadAll(resp.Body)
+			assert.Nil(t, err)
+			assert.Equal(t, c.body, string(body))
+
+			mocker.RemoveResponder(http.MethodGet, mockGoogleUrl)
+			_, err = cli.Get(mockGoogleUrl)
+			assert.NotNil(t, err)
+		})
+	}
+}
+
+func TestStartWithClientRestoresTransport(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, mockGoogleStrResp))
+
+	origin := &http.Transport{}
+	cli := &http.Client{Transport: origin}
+	mocker.StartWithClient(cli)
+	assert.NotEqual(t, origin, cli.Transport)
+
+	resp, err := cli.Get(mockGoogleUrl)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	mocker.Shutdown()
+	assert.Equal(t, origin, cli.Transport)
+}
+
+func TestPackageStartTracksOriginTransport(t *testing.T) {
+	origin := http.DefaultTransport
+	easymock.Start()
+	assert.Equal(t, origin, easymock.OriginTransport)
+	assert.Equal(t, easymock.MockerTransport, http.DefaultTransport)
+
+	cli := &http.Client{Transport: &http.Transport{}}
+	transport := cli.Transport
+	easymock.StartWithClient(cli)
+	assert.Equal(t, transport, easymock.OldClients[cli])
+
+	easymock.Shutdown()
+	assert.Equal(t, origin, http.DefaultTransport)
+	assert.Equal(t, transport, cli.Transport)
+	assert.Empty(t, easymock.OldClients)
+}
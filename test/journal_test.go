@@ -0,0 +1,95 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestJournalCapturesRequests(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodPost, mockGoogleUrl, easymock.NewEasyResponderWithReqHandler(
+		func(req *http.Request) (*http.Response, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			return easymock.NewHttpResponseWithBytes(http.StatusCreated, body), nil
+		}))
+	mocker.RegisterRegexResponder(http.MethodGet, mockBookUrlPattern, easymock.NewEasyRegexResponderWithReqHandler(
+		func(req *http.Request) (*http.Response, error) {
+			return easymock.NewHttpResponseWithString(http.StatusOK, ""), nil
+		}))
+	cli := &http.Client{Transport: mocker}
+
+	req, err := http.NewRequest(http.MethodPost, mockGoogleUrl, strings.NewReader(`{"name":"sjl"}`))
+	assert.Nil(t, err)
+	req.Header.Set("X-Trace", "abc")
+	resp, err := cli.Do(req)
+	assert.Nil(t, err)
+	echoed, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, `{"name":"sjl"}`, string(echoed))
+
+	_, err = cli.Get("https://www.easymock.com/books/3")
+	assert.Nil(t, err)
+	_, err = cli.Get(mockNoResponseUrl)
+	assert.NotNil(t, err)
+
+	journal := mocker.Journal()
+	assert.Len(t, journal, 3)
+
+	posted := mocker.Requests(http.MethodPost, mockGoogleUrl)
+	assert.Len(t, posted, 1)
+	assert.Equal(t, `{"name":"sjl"}`, string(posted[0].Body))
+	assert.Equal(t, "abc", posted[0].Header.Get("X-Trace"))
+	assert.Equal(t, http.StatusCreated, posted[0].StatusCode)
+	assert.True(t, posted[0].Matched)
+	assert.Equal(t, "POST "+mockGoogleUrl, posted[0].Route)
+	assert.False(t, posted[0].Time.IsZero())
+
+	books := mocker.RegexRequests(http.MethodGet, mockBookUrlPattern)
+	assert.Len(t, books, 1)
+	assert.Equal(t, "https://www.easymock.com/books/3", books[0].URL)
+
+	unmatched := mocker.UnmatchedRequests()
+	assert.Len(t, unmatched, 1)
+	assert.False(t, unmatched[0].Matched)
+	assert.NotNil(t, unmatched[0].Err)
+
+	last, ok := mocker.LastRequest()
+	assert.True(t, ok)
+	assert.Equal(t, mockNoResponseUrl, last.URL)
+
+	mocker.ResetJournal()
+	assert.Empty(t, mocker.Journal())
+}
+
+func TestJournalLeavesRequestUntouched(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodPost, mockGoogleUrl, easymock.NewEasyResponderWithReqHandler(
+		func(req *http.Request) (*http.Response, error) {
+			body, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			return easymock.NewHttpResponseWithBytes(http.StatusOK, body), nil
+		}))
+
+	body := ioutil.NopCloser(strings.NewReader("payload"))
+	req, err := http.NewRequest(http.MethodPost, mockGoogleUrl, body)
+	assert.Nil(t, err)
+	assert.Nil(t, req.GetBody)
+	resp, err := mocker.RoundTrip(req)
+	assert.Equal(t, "payload", readBody(t, resp, err))
+	assert.True(t, req.Body == body)
+	assert.Nil(t, req.GetBody)
+	assert.True(t, resp.Request != req)
+
+	requests := mocker.Requests(http.MethodPost, mockGoogleUrl)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "payload", string(requests[0].Body))
+}
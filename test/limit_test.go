@@ -0,0 +1,76 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOnceResponderFallsThrough(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, "steady"))
+	oneShot := easymock.NewStringEasyResponder(http.StatusInternalServerError, "boom").Once()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, oneShot)
+	cli := &http.Client{Transport: mocker}
+
+	assert.Equal(t, []int{500, 200, 200}, collectStatuses(t, cli, 3))
+	assert.Equal(t, 1, oneShot.Uses())
+	assert.False(t, oneShot.IsAvailable())
+
+	oneShot.ResetUses()
+	assert.Equal(t, []int{500, 200}, collectStatuses(t, cli, 2))
+}
+
+func TestTimesResponderFallsBackToUnmatchedPolicy(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterRegexResponder(http.MethodGet, mockBookUrlPattern, newNamedRegexResponder("limited").Times(2))
+	cli := &http.Client{Transport: mocker}
+
+	for i := 0; i < 2; i++ {
+		resp, err := cli.Get("https://www.easymock.com/books/1")
+		assert.Equal(t, "limited", readBody(t, resp, err))
+	}
+	_, err := cli.Get("https://www.easymock.com/books/1")
+	assert.NotNil(t, err)
+
+	mocker.RespondUnmatched(easymock.NewStringEasyResponder(http.StatusNotFound, ""))
+	resp, err := cli.Get("https://www.easymock.com/books/1")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestTimesResponderUnderConcurrency(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, "").Times(5))
+	cli := &http.Client{Transport: mocker}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, _ = cli.Get(mockGoogleUrl)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 5, mocker.CallCount(http.MethodGet, mockGoogleUrl))
+	assert.Equal(t, 15, mocker.UnmatchedCallCount())
+}
+
+func TestExpiredResponder(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, "steady"))
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl,
+		easymock.NewStringEasyResponder(http.StatusAccepted, "fresh").ExpireAfter(50*time.Millisecond))
+	cli := &http.Client{Transport: mocker}
+
+	assert.Equal(t, []int{202}, collectStatuses(t, cli, 1))
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, []int{200}, collectStatuses(t, cli, 1))
+
+	expired := easymock.NewStringEasyResponder(http.StatusOK, "").ExpireAt(time.Now().Add(-time.Second))
+	assert.False(t, expired.IsAvailable())
+}
@@ -0,0 +1,73 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const (
+	mockLoginUrl      = "https://www.easymock.com/login"
+	mockBookUrlPrefix = `^https://www\.easymock\.com/books/[0-9]+`
+)
+
+func TestMatchersSelectResponder(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodPost, mockLoginUrl, easymock.NewStringEasyResponder(http.StatusUnauthorized, "denied"))
+	mocker.RegisterResponder(http.MethodPost, mockLoginUrl, easymock.NewStringEasyResponder(http.StatusOK, "admin").
+		When(easymock.JSONBodyFieldEquals("$.user.roles[0]", "admin"), easymock.HeaderEquals("X-Client", "web")))
+	mocker.RegisterResponder(http.MethodPost, mockLoginUrl, easymock.NewStringEasyResponder(http.StatusOK, "token").
+		When(easymock.Or(easymock.BearerTokenEquals("secret"), easymock.BasicAuthEquals("sjl", "pwd"))))
+	cli := &http.Client{Transport: mocker}
+
+	req, _ := http.NewRequest(http.MethodPost, mockLoginUrl, strings.NewReader(`{"user":{"roles":["admin"]}}`))
+	req.Header.Set("X-Client", "web")
+	resp, err := cli.Do(req)
+	assert.Equal(t, "admin", readBody(t, resp, err))
+
+	req, _ = http.NewRequest(http.MethodPost, mockLoginUrl, strings.NewReader(`{"user":{"roles":["guest"]}}`))
+	req.Header.Set("X-Client", "web")
+	resp, err = cli.Do(req)
+	assert.Equal(t, "denied", readBody(t, resp, err))
+
+	req, _ = http.NewRequest(http.MethodPost, mockLoginUrl, nil)
+	req.SetBasicAuth("sjl", "pwd")
+	resp, err = cli.Do(req)
+	assert.Equal(t, "token", readBody(t, resp, err))
+
+	req, _ = http.NewRequest(http.MethodPost, mockLoginUrl, nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err = cli.Do(req)
+	assert.Equal(t, "token", readBody(t, resp, err))
+
+	assert.Equal(t, 4, mocker.CallCount(http.MethodPost, mockLoginUrl))
+}
+
+func TestMatchersOnRegexResponder(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterRegexResponder(http.MethodPost, mockBookUrlPrefix, newNamedRegexResponder("form").
+		When(easymock.FormFieldEquals("name", "sjl"), easymock.Not(easymock.CookiePresent("session"))))
+	mocker.RegisterRegexResponder(http.MethodPost, mockBookUrlPrefix, newNamedRegexResponder("raw").
+		When(easymock.BodyMatches(`^raw-[0-9]+$`), easymock.QueryEquals("v", "2")))
+	cli := &http.Client{Transport: mocker}
+
+	resp, err := cli.PostForm("https://www.easymock.com/books/1", url.Values{"name": {"sjl"}})
+	assert.Equal(t, "form", readBody(t, resp, err))
+
+	resp, err = cli.Post("https://www.easymock.com/books/1?v=2", "text/plain", strings.NewReader("raw-42"))
+	assert.Equal(t, "raw", readBody(t, resp, err))
+
+	req, _ := http.NewRequest(http.MethodPost, "https://www.easymock.com/books/1", strings.NewReader("name=sjl"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.AddCookie(&http.Cookie{Name: "session", Value: "1"})
+	_, err = cli.Do(req)
+	assert.NotNil(t, err)
+
+	assert.Panics(t, func() {
+		mocker.RegisterRegexResponder(http.MethodPost, mockBookUrlPrefix, newNamedRegexResponder("a"))
+		mocker.RegisterRegexResponder(http.MethodPost, mockBookUrlPrefix, newNamedRegexResponder("b"))
+	})
+}
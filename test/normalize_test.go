@@ -0,0 +1,85 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+const mockSearchUrl = "https://www.easymock.com/search?a=1&b=2&b=3"
+
+func TestExactRouteMatchesNormalizedUrl(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockSearchUrl, easymock.NewStringEasyResponder(http.StatusOK, "found"))
+	cli := &http.Client{Transport: mocker}
+
+	matched := []string{
+		"https://www.easymock.com/search?a=1&b=2&b=3",
+		"https://www.easymock.com/search?b=3&a=1&b=2",
+		"https://WWW.easymock.com:443/search/?b=2&b=3&a=1",
+		"https://www.easymock.com/%73earch?a=%31&b=2&b=3",
+	}
+	for _, u := range matched {
+		resp, err := cli.Get(u)
+		assert.Equal(t, "found", readBody(t, resp, err), u)
+	}
+
+	unmatched := []string{
+		"https://www.easymock.com/search?a=1&b=2",
+		"https://www.easymock.com/search?a=1&b=2&b=3&c=4",
+		"http://www.easymock.com/search?a=1&b=2&b=3",
+		"https://www.easymock.com:8443/search?a=1&b=2&b=3",
+	}
+	for _, u := range unmatched {
+		_, err := cli.Get(u)
+		assert.NotNil(t, err, u)
+	}
+
+	assert.Equal(t, len(matched), mocker.CallCount(http.MethodGet, "https://www.easymock.com/search?b=2&b=3&a=1"))
+}
+
+func TestQueryMatchModes(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponderWithQuery(http.MethodGet, "https://www.easymock.com/items", easymock.QueryIgnore,
+		easymock.NewStringEasyResponder(http.StatusOK, "any"))
+	mocker.RegisterResponderWithQuery(http.MethodGet, "https://www.easymock.com/items?page=2", easymock.QuerySubset,
+		easymock.NewStringEasyResponder(http.StatusOK, "page two"))
+	mocker.RegisterResponder(http.MethodGet, "https://www.easymock.com/items?page=2&size=10",
+		easymock.NewStringEasyResponder(http.StatusOK, "exact"))
+	cli := &http.Client{Transport: mocker}
+
+	resp, err := cli.Get("https://www.easymock.com/items?size=10&page=2")
+	assert.Equal(t, "exact", readBody(t, resp, err))
+	resp, err = cli.Get("https://www.easymock.com/items?page=2&sort=asc")
+	assert.Equal(t, "page two", readBody(t, resp, err))
+	resp, err = cli.Get("https://www.easymock.com/items?page=3")
+	assert.Equal(t, "any", readBody(t, resp, err))
+	resp, err = cli.Get("https://www.easymock.com/items")
+	assert.Equal(t, "any", readBody(t, resp, err))
+
+	assert.Panics(t, func() {
+		mocker.RegisterResponderWithQuery(http.MethodGet, "https://www.easymock.com/items/", easymock.QueryIgnore,
+			easymock.NewStringEasyResponder(http.StatusOK, ""))
+	})
+
+	mocker.RemoveResponder(http.MethodGet, "https://www.easymock.com/items?page=2")
+	resp, err = cli.Get("https://www.easymock.com/items?page=2&sort=asc")
+	assert.Equal(t, "any", readBody(t, resp, err))
+}
+
+func TestQueryIgnoreRouteLookup(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponderWithQuery(http.MethodGet, mockSearchUrl, easymock.QueryIgnore,
+		easymock.NewStringEasyResponder(http.StatusOK, "any"))
+	cli := &http.Client{Transport: mocker}
+
+	resp, err := cli.Get("https://www.easymock.com/search?q=go")
+	assert.Equal(t, "any", readBody(t, resp, err))
+	assert.Equal(t, 1, mocker.CallCount(http.MethodGet, mockSearchUrl))
+	assert.Len(t, mocker.Requests(http.MethodGet, mockSearchUrl), 1)
+	assert.Equal(t, 0, mocker.CallCount(http.MethodGet, "https://www.easymock.com/search?q=go"))
+
+	mocker.RemoveResponder(http.MethodGet, mockSearchUrl)
+	assert.Empty(t, mocker.Routes())
+}
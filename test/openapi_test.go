@@ -0,0 +1,154 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const mockPetStore = `openapi: 3.0.3
+servers:
+  - url: https://{env}.easymock.com/v1
+    variables:
+      env:
+        default: petstore
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema: {type: integer, maximum: 100}
+      responses:
+        "200":
+          content:
+            application/json:
+              schema:
+                type: array
+                items: {$ref: "#/components/schemas/Pet"}
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/Pet"}
+      responses:
+        "201":
+          content:
+            application/json:
+              examples:
+                created:
+                  value: {id: 7, name: rex}
+        "400":
+          description: invalid
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        required: true
+        schema: {type: integer}
+    get:
+      responses:
+        default:
+          content:
+            application/json:
+              schema: {$ref: "#/components/schemas/Pet"}
+    delete:
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      additionalProperties: false
+      properties:
+        id: {type: integer, minimum: 1}
+        name: {type: string, example: doggie}
+        tag: {type: string, enum: [dog, cat]}
+`
+
+func TestRegisterOpenAPI(t *testing.T) {
+	doc, err := easymock.ParseOpenAPI("petstore.yaml", []byte(mockPetStore))
+	assert.Nil(t, err)
+	mocker := easymock.NewEasyMockerTransport()
+	assert.Nil(t, mocker.RegisterOpenAPI(doc, easymock.OpenAPIOptions{}))
+	client := &http.Client{Transport: mocker}
+
+	resp, err := client.Get("https://petstore.easymock.com/v1/pets")
+	assert.Equal(t, `[{"id":1,"name":"doggie","tag":"dog"}]`, readBody(t, resp, err))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	resp, err = client.Post("https://petstore.easymock.com/v1/pets", "application/json", strings.NewReader(`{}`))
+	assert.Equal(t, `{"id":7,"name":"rex"}`, readBody(t, resp, err))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = client.Get("https://petstore.easymock.com/v1/pets/abc")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodDelete, "https://petstore.easymock.com/v1/pets/3", nil)
+	resp, err = client.Do(req)
+	assert.Equal(t, "", readBody(t, resp, err))
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestRegisterOpenAPIIsAtomic(t *testing.T) {
+	doc, err := easymock.ParseOpenAPI("petstore.yaml", []byte(mockPetStore))
+	assert.Nil(t, err)
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterTemplateResponder(http.MethodDelete, "https://petstore.easymock.com/v1/pets/{petId}",
+		easymock.NewStringEasyResponder(http.StatusOK, "deleted"))
+
+	err = mocker.RegisterOpenAPI(doc, easymock.OpenAPIOptions{})
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "DELETE /pets/{petId}")
+	}
+	assert.Len(t, mocker.Routes(), 1)
+
+	doc, err = easymock.ParseOpenAPI("null.yaml", []byte("openapi: 3.0.3\npaths:\n  /pets:\n"))
+	assert.Nil(t, err)
+	err = mocker.RegisterOpenAPI(doc, easymock.OpenAPIOptions{BaseURL: "http://localhost:8080"})
+	if assert.NotNil(t, err) {
+		assert.Equal(t, "/pets: path item is null", err.Error())
+	}
+}
+
+func TestOpenAPIRequestValidation(t *testing.T) {
+	doc, err := easymock.ParseOpenAPI("petstore.yaml", []byte(mockPetStore))
+	assert.Nil(t, err)
+	mocker := easymock.NewEasyMockerTransport()
+	opts := easymock.OpenAPIOptions{BaseURL: "http://localhost:8080/api", ValidateRequests: true}
+	assert.Nil(t, mocker.RegisterOpenAPI(doc, opts))
+	client := &http.Client{Transport: mocker}
+
+	resp, err := client.Get("http://localhost:8080/api/pets?limit=500")
+	assert.Equal(t, `{"errors":["query parameter 'limit' must be at most 100"]}`, readBody(t, resp, err))
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = client.Get("http://localhost:8080/api/pets/abc")
+	assert.Equal(t, `{"errors":["path parameter 'petId' 'abc' is not a valid integer"]}`, readBody(t, resp, err))
+
+	resp, err = client.Post("http://localhost:8080/api/pets", "application/json",
+		strings.NewReader(`{"id": 0, "tag": "bird", "age": 3}`))
+	body := readBody(t, resp, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+	for _, msg := range []string{
+		"request body.name is required",
+		"request body.age is not allowed",
+		"request body.id must be at least 1",
+		"request body.tag must be one of [dog cat]",
+	} {
+		assert.Contains(t, body, msg)
+	}
+
+	resp, err = client.Post("http://localhost:8080/api/pets", "text/plain", strings.NewReader(`rex`))
+	assert.Contains(t, readBody(t, resp, err), "content type 'text/plain' is not accepted")
+
+	resp, err = client.Post("http://localhost:8080/api/pets", "application/json", strings.NewReader(`{"name": "rex"}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func newUpstream(t *testing.T) *httptest.Server {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := ioutil.ReadAll(r.Body)
+		w.Header().Set("X-Upstream", "yes")
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = w.Write([]byte(r.Method + " " + r.URL.Path + " " + string(body) + " #" + strconv.Itoa(calls)))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	upstream := newUpstream(t)
+	dir, err := ioutil.TempDir("", "easymock")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cassette.json")
+
+	recording := easymock.NewEasyMockerTransport()
+	recording.StartRecording(easymock.RecordOptions{RedactHeaders: []string{"authorization", "Set-Cookie"}})
+	cli := &http.Client{Transport: recording}
+
+	req, _ := http.NewRequest(http.MethodPost, upstream.URL+"/items", strings.NewReader("a"))
+	req.Header.Set("Authorization", "Bearer token")
+	resp, err := cli.Do(req)
+	assert.Equal(t, "POST /items a #1", readBody(t, resp, err))
+	resp, err = cli.Post(upstream.URL+"/items", "text/plain", strings.NewReader("b"))
+	assert.Equal(t, "POST /items b #2", readBody(t, resp, err))
+	resp, err = cli.Post(upstream.URL+"/items", "text/plain", strings.NewReader("a"))
+	assert.Equal(t, "POST /items a #3", readBody(t, resp, err))
+
+	cassette := recording.StopRecording()
+	assert.Len(t, cassette.Interactions, 3)
+	assert.Equal(t, "REDACTED", cassette.Interactions[0].Request.Header.Get("Authorization"))
+	assert.Equal(t, "REDACTED", cassette.Interactions[0].Response.Header.Get("Set-Cookie"))
+	assert.Nil(t, cassette.Save(path))
+	upstream.Close()
+
+	loaded, err := easymock.LoadCassette(path)
+	assert.Nil(t, err)
+	replaying := easymock.NewEasyMockerTransport()
+	assert.Nil(t, replaying.Replay(loaded, easymock.ReplayOptions{MatchBody: true}))
+	cli = &http.Client{Transport: replaying}
+
+	resp, err = cli.Post(upstream.URL+"/items", "text/plain", strings.NewReader("b"))
+	assert.Equal(t, "POST /items b #2", readBody(t, resp, err))
+	assert.Equal(t, "yes", resp.Header.Get("X-Upstream"))
+	resp, err = cli.Post(upstream.URL+"/items", "text/plain", strings.NewReader("a"))
+	assert.Equal(t, "POST /items a #1", readBody(t, resp, err))
+	resp, err = cli.Post(upstream.URL+"/items", "text/plain", strings.NewReader("a"))
+	assert.Equal(t, "POST /items a #3", readBody(t, resp, err))
+	_, err = cli.Post(upstream.URL+"/items", "text/plain", strings.NewReader("c"))
+	assert.NotNil(t, err)
+}
+
+func TestReplayMatchingOnHeaders(t *testing.T) {
+	cassette := &easymock.Cassette{Interactions: []easymock.Interaction{
+		{
+			Request:  easymock.RecordedRequest{Method: http.MethodGet, URL: mockGoogleUrl, Header: http.Header{"Accept": {"text/plain"}}},
+			Response: easymock.RecordedResponse{StatusCode: http.StatusOK, Body: []byte("text")},
+		},
+		{
+			Request:  easymock.RecordedRequest{Method: http.MethodGet, URL: mockGoogleUrl, Header: http.Header{"Accept": {"application/json"}}},
+			Response: easymock.RecordedResponse{StatusCode: http.StatusOK, Body: []byte("{}")},
+		},
+	}}
+	mocker := easymock.NewEasyMockerTransport()
+	assert.Nil(t, mocker.Replay(cassette, easymock.ReplayOptions{MatchHeaders: []string{"Accept"}}))
+	cli := &http.Client{Transport: mocker}
+
+	req, _ := http.NewRequest(http.MethodGet, mockGoogleUrl, nil)
+	req.Header.Set("Accept", "application/json")
+	resp, err := cli.Do(req)
+	assert.Equal(t, "{}", readBody(t, resp, err))
+	req.Header.Set("Accept", "text/plain")
+	resp, err = cli.Do(req)
+	assert.Equal(t, "text", readBody(t, resp, err))
+}
+
+func TestReplayConflictReturnsError(t *testing.T) {
+	cassette := &easymock.Cassette{Interactions: []easymock.Interaction{
+		{
+			Request:  easymock.RecordedRequest{Method: http.MethodGet, URL: mockNoResponseUrl},
+			Response: easymock.RecordedResponse{StatusCode: http.StatusOK, Body: []byte("replayed")},
+		},
+		{
+			Request:  easymock.RecordedRequest{Method: http.MethodGet, URL: mockGoogleUrl},
+			Response: easymock.RecordedResponse{StatusCode: http.StatusOK, Body: []byte("replayed")},
+		},
+	}}
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, mockGoogleStrResp))
+
+	assert.NotPanics(t, func() {
+		err := mocker.Replay(cassette, easymock.ReplayOptions{})
+		if assert.NotNil(t, err) {
+			assert.Contains(t, err.Error(), "already exists")
+		}
+	})
+	assert.Len(t, mocker.RouteInfos(), 1)
+	_, err := (&http.Client{Transport: mocker}).Get(mockNoResponseUrl)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,67 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"testing"
+)
+
+func newNamedRegexResponder(name string) *easymock.EasyRegexResponder {
+	return easymock.NewEasyRegexResponderWithReqHandler(func(req *http.Request) (*http.Response, error) {
+		return easymock.NewHttpResponseWithString(http.StatusOK, name), nil
+	})
+}
+
+func readBody(t *testing.T, resp *http.Response, err error) string {
+	t.Helper()
+	if !assert.Nil(t, err) {
+		return ""
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	return string(body)
+}
+
+func TestRegexResponderRespectsMethod(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterRegexResponder(http.MethodGet, mockBookUrlPattern, newNamedRegexResponder("get"))
+	mocker.RegisterRegexResponder(http.MethodDelete, mockBookUrlPattern, newNamedRegexResponder("delete"))
+	cli := &http.Client{Transport: mocker}
+
+	resp, err := cli.Get("https://www.easymock.com/books/1")
+	assert.Equal(t, "get", readBody(t, resp, err))
+
+	req, _ := http.NewRequest(http.MethodDelete, "https://www.easymock.com/books/1", nil)
+	resp, err = cli.Do(req)
+	assert.Equal(t, "delete", readBody(t, resp, err))
+
+	_, err = cli.Post("https://www.easymock.com/books/1", "text/plain", nil)
+	assert.NotNil(t, err)
+}
+
+func TestOverlappingRegexRespondersAreDeterministic(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterRegexResponder(http.MethodGet, `^https://www\.easymock\.com/books/.*$`, newNamedRegexResponder("wide"))
+	mocker.RegisterRegexResponder(http.MethodGet, mockBookUrlPattern, newNamedRegexResponder("narrow"))
+	cli := &http.Client{Transport: mocker}
+
+	for i := 0; i < 20; i++ {
+		resp, err := cli.Get("https://www.easymock.com/books/1")
+		assert.Equal(t, "wide", readBody(t, resp, err))
+	}
+
+	narrow := newNamedRegexResponder("prioritized")
+	narrow.SetPriority(10)
+	mocker.RemoveRegexResponder(http.MethodGet, mockBookUrlPattern)
+	mocker.RegisterRegexResponder(http.MethodGet, mockBookUrlPattern, narrow)
+	for i := 0; i < 20; i++ {
+		resp, err := cli.Get("https://www.easymock.com/books/1")
+		assert.Equal(t, "prioritized", readBody(t, resp, err))
+	}
+
+	narrow.Disable()
+	resp, err := cli.Get("https://www.easymock.com/books/1")
+	assert.Equal(t, "wide", readBody(t, resp, err))
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const mockInvoiceTemplate = "https://api.easymock.com/invoices/{id}"
+
+func TestResponseTemplates(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	responder, err := easymock.NewTemplateEasyResponder(http.StatusCreated,
+		`{"id": "{{.PathParam "id"}}", "note": {{json (.JSON "note")}}, "tags": "{{range .QueryValues "tag"}}{{.}};{{end}}", "by": "{{.Header "X-User" | upper}}"}`,
+		map[string]string{"Location": "/invoices/{{.PathParam \"id\"}}", "X-Request-Id": "{{.RequestID}}"})
+	assert.Nil(t, err)
+	mocker.RegisterTemplateResponder(http.MethodPost, mockInvoiceTemplate, responder)
+	client := &http.Client{Transport: mocker}
+
+	req, _ := http.NewRequest(http.MethodPost, "https://api.easymock.com/invoices/42?tag=a&tag=b", strings.NewReader(`{"note": "fast"}`))
+	req.Header.Set("X-User", "sjl")
+	resp, err := client.Do(req)
+	assert.Equal(t, `{"id": "42", "note": "fast", "tags": "a;b;", "by": "SJL"}`, readBody(t, resp, err))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Equal(t, "/invoices/42", resp.Header.Get("Location"))
+	firstID := resp.Header.Get("X-Request-Id")
+	assert.Len(t, firstID, 36)
+
+	req, _ = http.NewRequest(http.MethodPost, "https://api.easymock.com/invoices/43", nil)
+	resp, err = client.Do(req)
+	assert.Equal(t, `{"id": "43", "note": null, "tags": "", "by": ""}`, readBody(t, resp, err))
+	assert.NotEqual(t, firstID, resp.Header.Get("X-Request-Id"))
+}
+
+func TestResponseTemplateErrors(t *testing.T) {
+	_, err := easymock.NewTemplateEasyResponder(http.StatusOK, "{{.PathParam", nil)
+	assert.NotNil(t, err)
+
+	mocker := easymock.NewEasyMockerTransport()
+	responder, err := easymock.NewTemplateEasyResponder(http.StatusOK, "{{.Missing}}", nil)
+	assert.Nil(t, err)
+	mocker.RegisterResponder(http.MethodGet, mockInvoiceTemplate, responder)
+	_, err = (&http.Client{Transport: mocker}).Get(mockInvoiceTemplate)
+	assert.NotNil(t, err)
+}
+
+func TestResponseTemplateDefinitions(t *testing.T) {
+	defs, err := easymock.ParseDefinitions("invoices.yaml", []byte(`routes:
+  - method: GET
+    template: https://api.easymock.com/invoices/{id}
+    response:
+      status: 200
+      template: true
+      headers:
+        Content-Type: application/json
+      body: '{"id": "{{.PathParam "id"}}", "status": "{{.Query "status" | default "open"}}"}'
+`))
+	assert.Nil(t, err)
+	mocker := easymock.NewEasyMockerTransport()
+	assert.Nil(t, mocker.RegisterDefinitions(defs))
+
+	resp, err := (&http.Client{Transport: mocker}).Get("https://api.easymock.com/invoices/7")
+	assert.Equal(t, `{"id": "7", "status": "open"}`, readBody(t, resp, err))
+	assert.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+
+	_, err = easymock.ParseDefinitions("bad.yaml", []byte(`routes:
+  - method: GET
+    url: https://api.easymock.com/invoices
+    response:
+      status: 200
+      template: true
+      json_body: {id: 1}
+      headers:
+        X-Id: '{{.PathParam'
+`))
+	if assert.NotNil(t, err) {
+		msg := err.Error()
+		assert.Contains(t, msg, "bad.yaml:6: routes[0].response.template: json_body cannot be a template")
+		assert.Contains(t, msg, "routes[0].response.headers.X-Id:")
+	}
+}
@@ -0,0 +1,76 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+const (
+	mockCartUrl      = "https://api.easymock.com/cart"
+	mockCartItemsUrl = "https://api.easymock.com/cart/items"
+)
+
+func TestScenarioTransitions(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockCartUrl,
+		easymock.NewStringEasyResponder(http.StatusOK, "empty").InScenario("cart").WhenState(easymock.ScenarioStarted))
+	mocker.RegisterResponder(http.MethodGet, mockCartUrl,
+		easymock.NewStringEasyResponder(http.StatusOK, "has items").InScenario("cart").WhenState("HAS_ITEMS"))
+	mocker.RegisterResponder(http.MethodPost, mockCartItemsUrl,
+		easymock.NewStringEasyResponder(http.StatusCreated, "").InScenario("cart").WillSetStateTo("HAS_ITEMS"))
+	client := &http.Client{Transport: mocker}
+
+	resp, err := client.Get(mockCartUrl)
+	assert.Equal(t, "empty", readBody(t, resp, err))
+	assert.Equal(t, easymock.ScenarioStarted, mocker.ScenarioState("cart"))
+
+	_, err = client.Post(mockCartItemsUrl, "application/json", nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]string{"cart": "HAS_ITEMS"}, mocker.Scenarios())
+	resp, err = client.Get(mockCartUrl)
+	assert.Equal(t, "has items", readBody(t, resp, err))
+
+	mocker.SetScenarioState("cart", "CHECKED_OUT")
+	_, err = client.Get(mockCartUrl)
+	assert.NotNil(t, err)
+
+	mocker.ResetScenarios()
+	resp, err = client.Get(mockCartUrl)
+	assert.Equal(t, "empty", readBody(t, resp, err))
+}
+
+func TestScenarioDefinitions(t *testing.T) {
+	defs, err := easymock.ParseDefinitions("cart.yaml", []byte(`routes:
+  - method: POST
+    url: https://api.easymock.com/cart/items
+    scenario: cart
+    new_state: HAS_ITEMS
+    response: {status: 201}
+  - method: GET
+    url: https://api.easymock.com/cart
+    scenario: cart
+    required_state: HAS_ITEMS
+    response: {status: 200, body: has items}
+  - method: GET
+    url: https://api.easymock.com/cart
+    required_state: HAS_ITEMS
+    response: {status: 200}
+`))
+	assert.Len(t, defs, 2)
+	assert.EqualError(t, err, "cart.yaml:14: routes[2].required_state: is only supported together with scenario")
+
+	mocker := easymock.NewEasyMockerTransport()
+	assert.Nil(t, mocker.RegisterDefinitions(defs))
+	client := &http.Client{Transport: mocker}
+	_, err = client.Get(mockCartUrl)
+	assert.NotNil(t, err)
+	_, err = client.Post(mockCartItemsUrl, "application/json", nil)
+	assert.Nil(t, err)
+	resp, err := client.Get(mockCartUrl)
+	assert.Equal(t, "has items", readBody(t, resp, err))
+
+	mocker.Reset()
+	assert.Empty(t, mocker.Scenarios())
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func newStatusSequence(exhausted easymock.SequenceExhausted) *easymock.EasyResponder {
+	return easymock.NewSequenceEasyResponder(exhausted,
+		easymock.NewHttpResponseWithString(http.StatusServiceUnavailable, "busy"),
+		easymock.NewHttpResponseWithString(http.StatusServiceUnavailable, "busy"),
+		easymock.NewHttpResponseWithString(http.StatusOK, "done"),
+	)
+}
+
+func collectStatuses(t *testing.T, cli *http.Client, n int) []int {
+	statuses := make([]int, 0, n)
+	for i := 0; i < n; i++ {
+		resp, err := cli.Get(mockGoogleUrl)
+		if err != nil {
+			statuses = append(statuses, 0)
+			continue
+		}
+		statuses = append(statuses, resp.StatusCode)
+	}
+	return statuses
+}
+
+func TestSequenceResponder(t *testing.T) {
+	cases := []struct {
+		name      string
+		exhausted easymock.SequenceExhausted
+		expected  []int
+	}{
+		{name: "repeat last", exhausted: easymock.RepeatLast, expected: []int{503, 503, 200, 200, 200}},
+		{name: "wrap around", exhausted: easymock.WrapAround, expected: []int{503, 503, 200, 503, 503}},
+		{name: "fail", exhausted: easymock.FailWhenExhausted, expected: []int{503, 503, 200, 0, 0}},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			mocker := easymock.NewEasyMockerTransport()
+			mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, newStatusSequence(c.exhausted))
+			cli := &http.Client{Transport: mocker}
+			assert.Equal(t, c.expected, collectStatuses(t, cli, len(c.expected)))
+		})
+	}
+}
+
+func TestSequenceResponderBodiesAreReusable(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, newStatusSequence(easymock.WrapAround))
+	cli := &http.Client{Transport: mocker}
+
+	bodies := make([]string, 0)
+	for i := 0; i < 6; i++ {
+		resp, err := cli.Get(mockGoogleUrl)
+		bodies = append(bodies, readBody(t, resp, err))
+	}
+	assert.Equal(t, []string{"busy", "busy", "done", "busy", "busy", "done"}, bodies)
+}
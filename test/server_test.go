@@ -0,0 +1,72 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestServeRegisteredResponders(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodPost, "https://api.easymock.com/books", easymock.NewStringEasyResponder(http.StatusCreated, "created"))
+	handler, err := mocker.Handler("https://api.easymock.com")
+	assert.Nil(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := server.Client().Post(server.URL+"/books", "text/plain", strings.NewReader("go"))
+	assert.Equal(t, "created", readBody(t, resp, err))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = server.Client().Get(server.URL + "/missing")
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadGateway, resp.StatusCode)
+
+	assert.Equal(t, 1, mocker.CallCount(http.MethodPost, "https://api.easymock.com/books"))
+	assert.Equal(t, 2, mocker.TotalCallCount())
+	last, ok := mocker.LastRequest()
+	assert.True(t, ok)
+	assert.Equal(t, "https://api.easymock.com/missing", last.URL)
+	assert.False(t, last.Matched)
+	entry := mocker.Requests(http.MethodPost, "https://api.easymock.com/books")[0]
+	assert.Equal(t, []byte("go"), entry.Body)
+	assert.Equal(t, []byte("created"), entry.RespBody)
+}
+
+func TestServeByHostAndProxy(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, "http://api.easymock.com/books", easymock.NewStringEasyResponder(http.StatusOK, "books"))
+	server := mocker.NewServer()
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/books", nil)
+	req.Host = "api.easymock.com"
+	resp, err := server.Client().Do(req)
+	assert.Equal(t, "books", readBody(t, resp, err))
+
+	proxy, _ := url.Parse(server.URL)
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxy)}}
+	resp, err = client.Get("http://api.easymock.com/books")
+	assert.Equal(t, "books", readBody(t, resp, err))
+	assert.Equal(t, 2, mocker.CallCount(http.MethodGet, "http://api.easymock.com/books"))
+}
+
+func TestServeFaultyBodyAbortsConnection(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterResponder(http.MethodGet, "http://api.easymock.com/stream",
+		easymock.NewConnResetEasyResponder(http.StatusOK, []byte("partial")))
+	handler, err := mocker.Handler("http://api.easymock.com")
+	assert.Nil(t, err)
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/stream")
+	assert.Nil(t, err)
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.NotNil(t, err)
+}
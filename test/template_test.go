@@ -0,0 +1,67 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+const mockOrderTemplate = "https://api.easymock.com/users/{id}/orders/{orderID:[0-9]+}"
+
+func TestTemplateResponderCapturesParams(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterTemplateResponder(http.MethodGet, mockOrderTemplate, easymock.NewEasyResponderWithReqHandler(
+		func(req *http.Request) (*http.Response, error) {
+			body := easymock.PathParam(req, "id") + "/" + easymock.PathParam(req, "orderID")
+			return easymock.NewHttpResponseWithString(http.StatusOK, body), nil
+		}))
+	cli := &http.Client{Transport: mocker}
+
+	resp, err := cli.Get("https://api.easymock.com/users/sjl%20cd/orders/42?verbose=true")
+	assert.Equal(t, "sjl cd/42", readBody(t, resp, err))
+
+	_, err = cli.Get("https://api.easymock.com/users/sjl/orders/abc")
+	assert.NotNil(t, err)
+	_, err = cli.Get("https://api.easymock.com/users/sjl/extra/orders/1")
+	assert.NotNil(t, err)
+	_, err = cli.Get("https://apixeasymock.com/users/sjl/orders/1")
+	assert.NotNil(t, err)
+
+	assert.Equal(t, 1, mocker.TemplateCallCount(http.MethodGet, mockOrderTemplate))
+	requests := mocker.TemplateRequests(http.MethodGet, mockOrderTemplate)
+	assert.Len(t, requests, 1)
+	assert.Equal(t, "GET "+mockOrderTemplate+" (template)", requests[0].Route)
+
+	mocker.RemoveTemplateResponder(http.MethodGet, mockOrderTemplate)
+	_, err = cli.Get("https://api.easymock.com/users/sjl/orders/42")
+	assert.NotNil(t, err)
+}
+
+func TestTemplateResponderRejectsInvalidTemplates(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	responder := easymock.NewStringEasyResponder(http.StatusOK, "")
+	assert.Panics(t, func() {
+		mocker.RegisterTemplateResponder(http.MethodGet, "https://api.easymock.com/users/{id", responder)
+	})
+	assert.Panics(t, func() {
+		mocker.RegisterTemplateResponder(http.MethodGet, "https://api.easymock.com/{id}/{id}", responder)
+	})
+	assert.Empty(t, easymock.PathParams(&http.Request{}))
+}
+
+func TestTemplateResponderNormalizesOrigin(t *testing.T) {
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterTemplateResponder(http.MethodGet, "HTTPS://API.easymock.com:443/users/{id}", easymock.NewEasyResponderWithReqHandler(
+		func(req *http.Request) (*http.Response, error) {
+			return easymock.NewHttpResponseWithString(http.StatusOK, easymock.PathParam(req, "id")), nil
+		}))
+	cli := &http.Client{Transport: mocker}
+
+	resp, err := cli.Get("https://api.easymock.com/users/7")
+	assert.Equal(t, "7", readBody(t, resp, err))
+	resp, err = cli.Get("https://Api.EasyMock.com:443/users/8")
+	assert.Equal(t, "8", readBody(t, resp, err))
+	_, err = cli.Get("https://api.easymock.com:8443/users/9")
+	assert.NotNil(t, err)
+}
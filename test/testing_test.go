@@ -0,0 +1,64 @@
+package test
+
+import (
+	"fmt"
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+type recordingTB struct {
+	testing.TB
+	errors   []string
+	cleanups []func()
+}
+
+func (r *recordingTB) Errorf(format string, args ...interface{}) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recordingTB) Cleanup(f func()) {
+	r.cleanups = append(r.cleanups, f)
+}
+
+func (r *recordingTB) Helper() {}
+
+func (r *recordingTB) runCleanups() {
+	for i := len(r.cleanups) - 1; i >= 0; i-- {
+		r.cleanups[i]()
+	}
+}
+
+func TestNewInstallsAndCleansUp(t *testing.T) {
+	origin := http.DefaultTransport
+	tb := &recordingTB{TB: t}
+	mocker := easymock.New(tb)
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, mockGoogleStrResp))
+
+	cli := new(http.Client)
+	mocker.StartWithClient(cli)
+	assert.Equal(t, mocker, http.DefaultTransport)
+
+	resp, err := http.Get(mockGoogleUrl)
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Empty(t, tb.errors)
+
+	tb.runCleanups()
+	assert.Equal(t, origin, http.DefaultTransport)
+	assert.Nil(t, cli.Transport)
+}
+
+func TestNewReportsUnmatchedRequests(t *testing.T) {
+	tb := &recordingTB{TB: t}
+	mocker := easymock.New(tb)
+	defer tb.runCleanups()
+	mocker.RegisterResponder(http.MethodGet, mockGoogleUrl, easymock.NewStringEasyResponder(http.StatusOK, mockGoogleStrResp))
+
+	_, err := http.Post(mockNoResponseUrl, "text/plain", nil)
+	assert.NotNil(t, err)
+	assert.Len(t, tb.errors, 1)
+	assert.Contains(t, tb.errors[0], "POST "+mockNoResponseUrl)
+	assert.Contains(t, tb.errors[0], "GET "+mockGoogleUrl)
+}
@@ -0,0 +1,139 @@
+package test
+
+import (
+	"github.com/SCU-SJL/easymock/easymock"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const mockWireMockUsers = `{
+  "request": {
+    "method": "GET",
+    "urlPathPattern": "/users/[0-9]+",
+    "queryParameters": {"verbose": {"equalTo": "true"}},
+    "headers": {"Accept": {"contains": "json"}}
+  },
+  "response": {
+    "status": 200,
+    "headers": {"X-Source": "wiremock"},
+    "jsonBody": {"name": "sjl"},
+    "fixedDelayMilliseconds": 1
+  }
+}`
+
+const mockWireMockOrders = `{
+  "mappings": [
+    {
+      "priority": 1,
+      "request": {
+        "method": "POST",
+        "urlPath": "/orders",
+        "bodyPatterns": [
+          {"equalToJson": "{\"items\": [1, 2]}", "ignoreArrayOrder": true, "ignoreExtraElements": true},
+          {"matchesJsonPath": {"expression": "$.customer.id", "matches": "c-[0-9]+"}}
+        ]
+      },
+      "response": {"status": 201, "bodyFileName": "order.json"}
+    },
+    {
+      "request": {"method": "POST", "urlPath": "/orders"},
+      "response": {"status": 400}
+    },
+    {
+      "request": {"method": "GET", "url": "/orders/reset"},
+      "response": {"fault": "CONNECTION_RESET_BY_PEER"}
+    },
+    {
+      "request": {"method": "GET", "urlPath": "/cart", "bodyPatterns": [{"equalToXml": "<cart/>"}]},
+      "response": {"status": 200, "transformers": ["response-template"]}
+    }
+  ]
+}`
+
+func TestImportWireMock(t *testing.T) {
+	dir := writeDefinitionFiles(t, nil)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "mappings"), 0755))
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "__files"), 0755))
+	for name, content := range map[string]string{
+		"mappings/users.json":  mockWireMockUsers,
+		"mappings/orders.json": mockWireMockOrders,
+		"__files/order.json":   `{"id":"o-1"}`,
+	} {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+
+	mocker := easymock.NewEasyMockerTransport()
+	err := mocker.ImportWireMock(dir)
+	errs, ok := err.(easymock.DefinitionErrors)
+	assert.True(t, ok)
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, "mappings[3].request.bodyPatterns[0].equalToXml", errs[0].Path)
+		assert.Equal(t, "unsupported WireMock feature", errs[0].Msg)
+		assert.Equal(t, "mappings[3].response.transformers", errs[1].Path)
+	}
+	client := &http.Client{Transport: mocker}
+
+	req, _ := http.NewRequest(http.MethodGet, "http://users.local/users/42?verbose=true", nil)
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	assert.Equal(t, `{"name":"sjl"}`, readBody(t, resp, err))
+	assert.Equal(t, "wiremock", resp.Header.Get("X-Source"))
+
+	req.Header.Set("Accept", "text/plain")
+	_, err = client.Do(req)
+	assert.NotNil(t, err)
+
+	resp, err = client.Post("https://shop.local/orders", "application/json",
+		strings.NewReader(`{"items": [2, 1], "customer": {"id": "c-7"}}`))
+	assert.Equal(t, `{"id":"o-1"}`, readBody(t, resp, err))
+	assert.Equal(t, http.StatusCreated, resp.StatusCode)
+
+	resp, err = client.Post("https://shop.local/orders", "application/json", strings.NewReader(`{"items": [3]}`))
+	assert.Nil(t, err)
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	_, err = client.Get("https://shop.local/orders/reset")
+	assert.Contains(t, err.Error(), "connection reset by peer")
+}
+
+func TestImportWireMockReportsDroppedFeaturesAndRollsBack(t *testing.T) {
+	dir := writeDefinitionFiles(t, nil)
+	defer os.RemoveAll(dir)
+	assert.Nil(t, os.MkdirAll(filepath.Join(dir, "mappings"), 0755))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "mappings", "ping.json"), []byte(`{
+  "mappings": [
+    {"request": {"method": "ANY", "url": "/ping"}, "response": {"status": 200, "body": "pong"}},
+    {"request": {"method": "GET", "url": "/status"}, "response": {"status": 200, "statusMessage": "Fine"}},
+    {
+      "request": {"method": "GET", "url": "/search", "headers": {"Accept": {"contains": "JSON", "caseInsensitive": true}}},
+      "response": {"status": 200}
+    }
+  ]
+}`), 0644))
+
+	mocker := easymock.NewEasyMockerTransport()
+	mocker.RegisterRegexResponder(http.MethodPost, `^[a-zA-Z][a-zA-Z0-9+.-]*://[^/]+/ping$`,
+		easymock.NewEasyRegexResponderWithReqHandler(func(req *http.Request) (*http.Response, error) {
+			return easymock.NewHttpResponseWithString(http.StatusOK, "registered before"), nil
+		}))
+	err := mocker.ImportWireMock(dir)
+	errs, ok := err.(easymock.DefinitionErrors)
+	assert.True(t, ok)
+	if assert.Len(t, errs, 3) {
+		assert.Equal(t, "mappings[1].response.statusMessage", errs[0].Path)
+		assert.Equal(t, "unsupported WireMock feature", errs[0].Msg)
+		assert.Equal(t, "mappings[0].request", errs[1].Path)
+		assert.Contains(t, errs[1].Msg, "already exists")
+		assert.Equal(t, "mappings[2].request", errs[2].Path)
+		assert.Equal(t, "headers.Accept: caseInsensitive: unsupported WireMock feature", errs[2].Msg)
+	}
+	assert.Len(t, mocker.RouteInfos(), 1)
+	_, err = (&http.Client{Transport: mocker}).Get("http://svc.local/ping")
+	assert.NotNil(t, err)
+}